@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"github.com/shortener/internal/service"
+	"go.uber.org/zap"
+)
+
+// Imports links from a CSV or NDJSON export of another shortener, keeping their
+// short codes. Usage: go run ./cmd/import -file links.csv [-format csv|ndjson] [-workspace id]
+func main() {
+	filePath := flag.String("file", "", "CSV or NDJSON file to import")
+	format := flag.String("format", "", "csv or ndjson, detected from the file extension when omitted")
+	workspace := flag.String("workspace", "", "workspace of the imported links, only admins can manage them when omitted")
+	flag.Parse()
+
+	if *filePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*filePath)) {
+		case ".csv":
+			*format = model.FormatCSV
+		case ".ndjson", ".jsonl":
+			*format = model.FormatNDJSON
+		}
+	}
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	if err := logger.Init(cfg.Server.Env); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	err := run(cfg, *filePath, *format, *workspace)
+	logger.Sync()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// run performs the import. It returns instead of exiting so the file and the
+// database connection are closed on every path.
+func run(cfg *config.Config, filePath, format, workspace string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		logger.Error("Failed to open import file", zap.Error(err))
+		return err
+	}
+	defer file.Close()
+
+	// Initialize database
+	db, err := repository.NewDatabase(cfg)
+	if err != nil {
+		logger.Error("Failed to connect to database", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	destinationPolicy, err := service.NewDomainPolicy(cfg)
+	if err != nil {
+		logger.Error("Failed to load destination policy", zap.Error(err))
+		return err
+	}
+	urlScanner, err := service.NewURLScanner(cfg)
+	if err != nil {
+		logger.Error("Failed to initialize URL scanner", zap.Error(err))
+		return err
+	}
+
+	importService := service.NewImportService(repository.NewShortURLRepository(db), destinationPolicy, urlScanner, cfg)
+	report, err := importService.Import(file, format, workspace)
+
+	// Print the report even when the import stopped early
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	}
+	if err != nil {
+		logger.Error("Import failed", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Import finished",
+		zap.Int("imported", report.Imported),
+		zap.Int("conflicts", report.Conflicts),
+		zap.Int("invalid", report.Invalid),
+	)
+	return nil
+}
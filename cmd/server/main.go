@@ -9,8 +9,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/shortener/internal/auth"
 	"github.com/shortener/internal/cache"
 	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/geoip"
 	"github.com/shortener/internal/handler"
 	"github.com/shortener/internal/logger"
 	"github.com/shortener/internal/repository"
@@ -48,6 +50,10 @@ func main() {
 	defer logger.Sync()
 
 	logger.Info("Starting URL Shortener Service", zap.String("env", cfg.Server.Env))
+	if cfg.App.IPHashSalt == "" || cfg.App.IPHashSalt == "change-me" {
+		// Unsalted IPv4 hashes can be reversed by hashing the whole address space
+		logger.Warn("IP_HASH_SALT is empty or the example value, stored IP hashes can be reversed to IP addresses; set a long random secret")
+	}
 
 	// Initialize database
 	db, err := repository.NewDatabase(cfg)
@@ -65,12 +71,58 @@ func main() {
 
 	// Initialize repositories
 	shortURLRepo := repository.NewShortURLRepository(db)
+	clickRepo := repository.NewClickRepository(db)
+	clickRollupRepo := repository.NewClickRollupRepository(db)
+	abuseReportRepo := repository.NewAbuseReportRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
 
 	// Initialize services
-	urlService := service.NewURLService(shortURLRepo, redisClient, cfg)
+	codeGenerator, err := service.NewCodeGenerator(shortURLRepo, cfg)
+	if err != nil {
+		logger.Fatal("Invalid short code configuration", zap.Error(err))
+	}
+	destinationPolicy, err := service.NewDomainPolicy(cfg)
+	if err != nil {
+		logger.Fatal("Failed to load destination policy", zap.Error(err))
+	}
+	destinationPolicy.Start()
+	urlScanner, err := service.NewURLScanner(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize URL scanner", zap.Error(err))
+	}
+	urlService := service.NewURLService(shortURLRepo, redisClient, codeGenerator, destinationPolicy, urlScanner, cfg)
+	importService := service.NewImportService(shortURLRepo, destinationPolicy, urlScanner, cfg)
+	exportService := service.NewExportService(shortURLRepo, clickRepo)
+	abuseService := service.NewAbuseService(abuseReportRepo, shortURLRepo, redisClient, redisClient, cfg)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, workspaceRepo, cfg)
+	workspaceService := service.NewWorkspaceService(workspaceRepo)
+	if cfg.Auth.Token == "" {
+		logger.Info("AUTH_TOKEN is not set, only API keys are accepted")
+	}
+	jwtVerifier, err := auth.NewVerifier(cfg)
+	if err != nil {
+		logger.Fatal("Failed to load JWKS", zap.Error(err))
+	}
+	var jwtAuthenticator service.Authenticator
+	if jwtVerifier != nil {
+		jwtAuthenticator = service.NewJWTAuthenticator(jwtVerifier, workspaceRepo, cfg)
+		logger.Info("JWT bearer tokens enabled")
+	}
+	clickPipeline := service.NewClickPipeline(clickRepo, cfg)
+	clickPipeline.Start()
+	geoResolver := geoip.NewResolver(cfg)
+	analyticsService := service.NewAnalyticsService(clickRepo, clickRollupRepo, clickPipeline, geoResolver, redisClient, cfg)
+
+	// Start click rollup aggregator
+	rollupAggregator := service.NewRollupAggregator(clickRollupRepo, cfg)
+	rollupAggregator.Start()
 
 	// Setup routes
-	router := handler.SetupRoutes(urlService, cfg)
+	router, err := handler.SetupRoutes(cfg.Server.TrustedProxies, urlService, analyticsService, importService, exportService, abuseService, apiKeyService, jwtAuthenticator, workspaceService)
+	if err != nil {
+		logger.Fatal("Failed to setup routes", zap.Error(err))
+	}
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -101,11 +153,23 @@ func main() {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Flush buffered clicks before closing the database
+	if err := clickPipeline.Shutdown(ctx); err != nil {
+		logger.Error("Click pipeline did not flush in time", zap.Error(err))
+	}
+	logger.Info("Click pipeline stopped", zap.Any("metrics", clickPipeline.Metrics()))
+
+	rollupAggregator.Stop()
+	destinationPolicy.Stop()
+
 	// Close database connection
 	if sqlDB, err := db.DB(); err == nil {
 		sqlDB.Close()
 	}
 
+	// Close GeoIP database
+	geoResolver.Close()
+
 	// Close Redis connection
 	redisClient.Close()
 
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// minUnknownKeyReload limits reloads triggered by tokens with an unknown kid,
+	// so garbage tokens can't hammer the JWKS endpoint
+	minUnknownKeyReload = 30 * time.Second
+	// maxJWKSSize caps the size of a JWKS document
+	maxJWKSSize = 1 << 20
+)
+
+// jwk is the subset of RFC 7517 fields needed for RSA and P-256 signing keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet holds the public keys of a JWKS document read from a file or URL.
+// Keys are reloaded once they are older than the refresh interval, and early
+// when a token names an unknown key, so rotated keys are picked up.
+type KeySet struct {
+	source   string
+	client   *http.Client
+	refresh  time.Duration
+	keys     atomic.Pointer[map[string]crypto.PublicKey]
+	mu       sync.Mutex
+	loadedAt time.Time
+	triedAt  time.Time
+}
+
+// NewKeySet loads the JWKS at source, an http(s) URL or a file path
+func NewKeySet(source string, refresh time.Duration, timeout time.Duration) (*KeySet, error) {
+	if refresh <= 0 {
+		refresh = 5 * time.Minute
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	s := &KeySet{
+		source:  source,
+		client:  &http.Client{Timeout: timeout},
+		refresh: refresh,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Key returns the key with the given ID. Tokens without a kid are accepted when
+// the set holds a single key.
+func (s *KeySet) Key(kid string) (crypto.PublicKey, error) {
+	s.maybeRefresh()
+
+	if key, ok := lookupKey(*s.keys.Load(), kid); ok {
+		return key, nil
+	}
+	if s.reloadForUnknownKey() {
+		if key, ok := lookupKey(*s.keys.Load(), kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// maybeRefresh reloads stale keys. Only one caller reloads at a time, the others
+// keep verifying with the loaded keys.
+func (s *KeySet) maybeRefresh() {
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	if time.Since(s.loadedAt) < s.refresh || time.Since(s.triedAt) < minUnknownKeyReload {
+		return
+	}
+	if err := s.load(); err != nil {
+		logger.Error("Failed to refresh JWKS, keeping previous keys", zap.String("source", s.source), zap.Error(err))
+	}
+}
+
+// reloadForUnknownKey reloads the keys unless that happened recently and reports
+// whether new keys were loaded
+func (s *KeySet) reloadForUnknownKey() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.triedAt) < minUnknownKeyReload {
+		return false
+	}
+	if err := s.load(); err != nil {
+		logger.Error("Failed to reload JWKS for unknown key", zap.String("source", s.source), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// load reads and parses the JWKS. Callers hold mu, except during construction.
+func (s *KeySet) load() error {
+	s.triedAt = time.Now()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse JWKS from %s: %w", s.source, err)
+	}
+
+	s.keys.Store(&keys)
+	s.loadedAt = s.triedAt
+	logger.Debug("JWKS loaded", zap.String("source", s.source), zap.Int("keys", len(keys)))
+	return nil
+}
+
+func (s *KeySet) read() ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		data, err := os.ReadFile(s.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	resp, err := s.client.Get(s.source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	return data, nil
+}
+
+// parseJWKS returns the RSA and P-256 signing keys of a JWKS document by kid.
+// Encryption keys and other key types are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsaKey()
+		case "EC":
+			key, err = k.ecKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	if n.BitLen() < 2048 {
+		return nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecKey() (*ecdsa.PublicKey, error) {
+	if k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x: %w", err)
+	}
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y: %w", err)
+	}
+	if x.BitLen() > 256 || y.BitLen() > 256 {
+		return nil, fmt.Errorf("coordinates are too long")
+	}
+
+	// Let crypto/ecdh reject points that are not on the curve
+	point := make([]byte, 65)
+	point[0] = 4
+	x.FillBytes(point[1:33])
+	y.FillBytes(point[33:])
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid point: %w", err)
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/shortener/internal/config"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, badly signed,
+// expired or issued for someone else
+var ErrInvalidToken = errors.New("invalid token")
+
+// Signing algorithms accepted for JWTs. Symmetric algorithms and "none" are
+// rejected so no shared secret has to be distributed.
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// KeySource resolves the public key of a token by its kid
+type KeySource interface {
+	Key(kid string) (crypto.PublicKey, error)
+}
+
+// Claims are the payload of a verified token
+type Claims map[string]interface{}
+
+// String returns a string claim, or "" when it is missing or not a string
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings returns a claim holding either a space separated string, like the
+// OAuth scope claim, or an array of strings
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Verifier checks the signature and registered claims of JWTs
+type Verifier struct {
+	keys     KeySource
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier loads the JWKS configured in AUTH_JWKS_URL or AUTH_JWKS_FILE.
+// It returns nil when neither is set, JWTs are not accepted then. The issuer and
+// audience are required, otherwise tokens the SSO issues for any other app
+// signed with the same keys would be accepted.
+func NewVerifier(cfg *config.Config) (*Verifier, error) {
+	source := cfg.Auth.JWKSURL
+	if source == "" {
+		source = cfg.Auth.JWKSFile
+	} else if cfg.Auth.JWKSFile != "" {
+		return nil, fmt.Errorf("only one of AUTH_JWKS_URL and AUTH_JWKS_FILE can be set")
+	}
+	if source == "" {
+		return nil, nil
+	}
+	if cfg.Auth.JWTIssuer == "" || cfg.Auth.JWTAudience == "" {
+		return nil, fmt.Errorf("AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE are required when JWTs are accepted")
+	}
+
+	keys, err := NewKeySet(source,
+		time.Duration(cfg.Auth.JWKSRefreshSec)*time.Second,
+		time.Duration(cfg.Auth.JWKSTimeoutMS)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifierWithKeys(keys, cfg.Auth.JWTIssuer, cfg.Auth.JWTAudience, time.Duration(cfg.Auth.JWTLeewaySec)*time.Second), nil
+}
+
+// NewVerifierWithKeys verifies tokens against the given keys. Tokens must be
+// issued by issuer for audience.
+func NewVerifierWithKeys(keys KeySource, issuer, audience string, leeway time.Duration) *Verifier {
+	return &Verifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		leeway:   leeway,
+		now:      time.Now,
+	}
+}
+
+// IsJWT reports whether a bearer token looks like a compact JWS rather than an API key
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify checks the token and returns its claims. The exp claim is required.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	key, err := v.keys.Key(header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// verifySignature checks the signature with the key, which must match the algorithm
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) error {
+	switch alg {
+	case AlgRS256:
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key does not match algorithm %s", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature); err != nil {
+			return fmt.Errorf("bad signature")
+		}
+	case AlgES256:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key does not match algorithm %s", alg)
+		}
+		// JWS encodes ECDSA signatures as the fixed size concatenation of r and s
+		if len(signature) != 64 {
+			return fmt.Errorf("bad signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("bad signature")
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return nil
+}
+
+func (v *Verifier) checkClaims(claims Claims) error {
+	now := v.now()
+
+	exp, ok := numericDate(claims, "exp")
+	if !ok {
+		return fmt.Errorf("missing exp")
+	}
+	if !now.Before(exp.Add(v.leeway)) {
+		return fmt.Errorf("token expired")
+	}
+	if nbf, ok := numericDate(claims, "nbf"); ok && now.Add(v.leeway).Before(nbf) {
+		return fmt.Errorf("token not valid yet")
+	}
+
+	if claims.String("iss") != v.issuer {
+		return fmt.Errorf("unexpected issuer")
+	}
+	if !contains(claims.Strings("aud"), v.audience) {
+		return fmt.Errorf("unexpected audience")
+	}
+	return nil
+}
+
+func numericDate(claims Claims, name string) (time.Time, bool) {
+	value, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(value), 0), true
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func contains(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
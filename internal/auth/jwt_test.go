@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// testKeys are the signing keys of a test JWKS
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return &testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+func encodeBigInt(n *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(n.FillBytes(make([]byte, size)))
+}
+
+// writeJWKS writes the public keys as a JWKS file, the EC key has the kid "ec-1"
+func (k *testKeys) writeJWKS(t *testing.T, path string, rsaKid string) {
+	document := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": rsaKid, "use": "sig",
+				"n": encodeBigInt(k.rsa.N, k.rsa.Size()),
+				"e": encodeBigInt(big.NewInt(int64(k.rsa.E)), 3),
+			},
+			{
+				"kty": "EC", "kid": "ec-1", "crv": "P-256",
+				"x": encodeBigInt(k.ec.X, 32),
+				"y": encodeBigInt(k.ec.Y, 32),
+			},
+			{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+		},
+	}
+	data, err := json.Marshal(document)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+// sign builds a compact JWS with the given header algorithm and kid
+func (k *testKeys) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	assert.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	assert.NoError(t, err)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch alg {
+	case AlgRS256:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	case AlgES256:
+		r, s, err := ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		assert.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestVerifier(t *testing.T, keys *testKeys) *Verifier {
+	assert.NoError(t, logger.Init("test"))
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	keys.writeJWKS(t, path, "rsa-1")
+
+	keySet, err := NewKeySet(path, time.Hour, time.Second)
+	assert.NoError(t, err)
+	return NewVerifierWithKeys(keySet, "https://sso.example.com", "shortener", time.Minute)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":       "alice@example.com",
+		"iss":       "https://sso.example.com",
+		"aud":       []string{"shortener", "wiki"},
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"scope":     "links:read stats:read",
+		"workspace": "growth",
+	}
+}
+
+func TestVerify_RS256AndES256(t *testing.T) {
+	// Setup
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(t, keys)
+
+	for _, token := range []string{
+		keys.sign(t, AlgRS256, "rsa-1", validClaims()),
+		keys.sign(t, AlgES256, "ec-1", validClaims()),
+	} {
+		// Execute
+		claims, err := verifier.Verify(token)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "alice@example.com", claims.String("sub"))
+		assert.Equal(t, []string{"links:read", "stats:read"}, claims.Strings("scope"))
+		assert.True(t, IsJWT(token))
+	}
+}
+
+func TestVerify_Rejected(t *testing.T) {
+	// Setup
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(t, keys)
+
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	valid := keys.sign(t, AlgRS256, "rsa-1", validClaims())
+	parts := strings.Split(valid, ".")
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	tests := map[string]string{
+		"expired":          keys.sign(t, AlgRS256, "rsa-1", with("exp", time.Now().Add(-2*time.Minute).Unix())),
+		"missing exp":      keys.sign(t, AlgRS256, "rsa-1", with("exp", nil)),
+		"not yet valid":    keys.sign(t, AlgRS256, "rsa-1", with("nbf", time.Now().Add(time.Hour).Unix())),
+		"wrong issuer":     keys.sign(t, AlgRS256, "rsa-1", with("iss", "https://evil.example.com")),
+		"missing issuer":   keys.sign(t, AlgRS256, "rsa-1", with("iss", nil)),
+		"missing audience": keys.sign(t, AlgRS256, "rsa-1", with("aud", nil)),
+		"wrong audience":   keys.sign(t, AlgRS256, "rsa-1", with("aud", "wiki")),
+		"alg mismatch":     keys.sign(t, AlgES256, "rsa-1", validClaims()),
+		"unknown kid":      keys.sign(t, AlgRS256, "rsa-9", validClaims()),
+		"symmetric alg":    keys.sign(t, "HS256", "hmac", validClaims()),
+		"alg none":         unsigned,
+		"tampered claims":  parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory"}`)) + "." + parts[2],
+		"malformed":        "not.a-token",
+	}
+
+	for name, token := range tests {
+		// Execute
+		_, err := verifier.Verify(token)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+}
+
+func TestKeySet_ReloadsRotatedKeys(t *testing.T) {
+	// Setup
+	assert.NoError(t, logger.Init("test"))
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	keys.writeJWKS(t, path, "rsa-1")
+	keySet, err := NewKeySet(path, time.Hour, time.Second)
+	assert.NoError(t, err)
+
+	// Execute - the key is rotated and the last load is long enough ago
+	keys.writeJWKS(t, path, "rsa-2")
+	keySet.triedAt = time.Now().Add(-minUnknownKeyReload)
+	rotated, rotatedErr := keySet.Key("rsa-2")
+	_, unknownErr := keySet.Key("rsa-3")
+
+	// Assert
+	assert.NoError(t, rotatedErr)
+	assert.Equal(t, &keys.rsa.PublicKey, rotated)
+	assert.Error(t, unknownErr)
+}
+
+func TestNewVerifier_RequiresIssuerAndAudience(t *testing.T) {
+	// Setup
+	assert.NoError(t, logger.Init("test"))
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	newTestKeys(t).writeJWKS(t, path, "rsa-1")
+
+	tests := map[string]config.AuthConfig{
+		"missing audience": {JWKSFile: path, JWTIssuer: "https://sso.example.com"},
+		"missing issuer":   {JWKSFile: path, JWTAudience: "shortener"},
+	}
+	for name, authConfig := range tests {
+		// Execute
+		verifier, err := NewVerifier(&config.Config{Auth: authConfig})
+
+		// Assert
+		assert.Error(t, err, name)
+		assert.Nil(t, verifier, name)
+	}
+
+	verifier, err := NewVerifier(&config.Config{Auth: config.AuthConfig{JWKSFile: path, JWTIssuer: "https://sso.example.com", JWTAudience: "shortener"}})
+	assert.NoError(t, err)
+	assert.NotNil(t, verifier)
+	disabled, err := NewVerifier(&config.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, disabled)
+}
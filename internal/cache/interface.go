@@ -2,11 +2,34 @@ package cache
 
 import "time"
 
+// Entry is a single key/value pair written by SetMany
+type Entry struct {
+	Key        string
+	Value      string
+	Expiration time.Duration
+}
+
 // CacheInterface defines methods for cache operations
 type CacheInterface interface {
 	Set(key, value string, expiration time.Duration) error
+	SetMany(entries []Entry) error
 	Get(key string) (string, error)
 	Delete(key string) error
 	Ping() error
 	Close() error
 }
+
+// AnalyticsCache defines probabilistic counting operations used for click analytics
+type AnalyticsCache interface {
+	// PFAdd adds elements to the HyperLogLog at key, refreshing its expiration when > 0
+	PFAdd(key string, expiration time.Duration, elements ...string) error
+	// PFCount returns the approximate number of distinct elements across keys
+	PFCount(keys ...string) (int64, error)
+}
+
+// RateLimitCache defines fixed window counters used for rate limiting
+type RateLimitCache interface {
+	// Incr increments the counter at key and returns the new value. The window
+	// starts with the first increment, after which the counter expires.
+	Incr(key string, window time.Duration) (int64, error)
+}
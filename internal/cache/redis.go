@@ -14,7 +14,7 @@ type RedisClient struct {
 	ctx    context.Context
 }
 
-func NewRedisClient(cfg *config.Config) CacheInterface {
+func NewRedisClient(cfg *config.Config) *RedisClient {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
 		Password: cfg.Redis.Password,
@@ -31,6 +31,21 @@ func (r *RedisClient) Set(key, value string, expiration time.Duration) error {
 	return r.client.Set(r.ctx, key, value, expiration).Err()
 }
 
+// SetMany writes all entries in a single pipelined round trip
+func (r *RedisClient) SetMany(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	_, err := r.client.Pipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		for _, entry := range entries {
+			pipe.Set(r.ctx, entry.Key, entry.Value, entry.Expiration)
+		}
+		return nil
+	})
+	return err
+}
+
 func (r *RedisClient) Get(key string) (string, error) {
 	result, err := r.client.Get(r.ctx, key).Result()
 	if err == redis.Nil {
@@ -50,3 +65,38 @@ func (r *RedisClient) Ping() error {
 func (r *RedisClient) Close() error {
 	return r.client.Close()
 }
+
+func (r *RedisClient) PFAdd(key string, expiration time.Duration, elements ...string) error {
+	args := make([]interface{}, len(elements))
+	for i, element := range elements {
+		args[i] = element
+	}
+
+	if expiration <= 0 {
+		return r.client.PFAdd(r.ctx, key, args...).Err()
+	}
+
+	_, err := r.client.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		pipe.PFAdd(r.ctx, key, args...)
+		pipe.Expire(r.ctx, key, expiration)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisClient) PFCount(keys ...string) (int64, error) {
+	return r.client.PFCount(r.ctx, keys...).Result()
+}
+
+func (r *RedisClient) Incr(key string, window time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(r.ctx, key)
+		pipe.ExpireNX(r.ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
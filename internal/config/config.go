@@ -2,21 +2,28 @@ package config
 
 import (
 	"log"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	App      AppConfig      `mapstructure:"app"`
-	Auth     AuthConfig     `mapstructure:"auth"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	App       AppConfig       `mapstructure:"app"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	Analytics AnalyticsConfig `mapstructure:"analytics"`
+	URLs      URLConfig       `mapstructure:"urls"`
+	Abuse     AbuseConfig     `mapstructure:"abuse"`
 }
 
 type ServerConfig struct {
 	Port string `mapstructure:"port"`
 	Env  string `mapstructure:"env"`
+	// TrustedProxies are the proxy addresses or CIDRs whose X-Forwarded-For
+	// header is believed; without any the client IP is the peer address
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -36,13 +43,78 @@ type RedisConfig struct {
 }
 
 type AppConfig struct {
-	BaseURL         string `mapstructure:"base_url"`
-	CacheTTL        int    `mapstructure:"cache_ttl"`
-	ShortCodeLength int    `mapstructure:"short_code_length"`
+	BaseURL         string   `mapstructure:"base_url"`
+	CacheTTL        int      `mapstructure:"cache_ttl"`
+	ShortCodeLength int      `mapstructure:"short_code_length"`
+	AliasMinLength  int      `mapstructure:"alias_min_length"`
+	AliasMaxLength  int      `mapstructure:"alias_max_length"`
+	ReservedAliases []string `mapstructure:"reserved_aliases"`
+	IPHashSalt      string   `mapstructure:"ip_hash_salt"`
+	BatchMaxSize    int      `mapstructure:"batch_max_size"`
+	// CodeStrategy selects how short codes are minted: random or sequence
+	CodeStrategy       string `mapstructure:"code_strategy"`
+	CodeObfuscationKey string `mapstructure:"code_obfuscation_key"`
+	// ShortCodeMaxLength caps automatic growth of random codes
+	ShortCodeMaxLength     int     `mapstructure:"short_code_max_length"`
+	CodeCollisionThreshold float64 `mapstructure:"code_collision_threshold"`
+	// DeduplicateURLs returns existing short URLs for repeated destinations by default
+	DeduplicateURLs bool `mapstructure:"deduplicate_urls"`
+}
+
+// URLConfig controls how destinations are normalized and which ones are accepted
+type URLConfig struct {
+	SortQueryParams          bool     `mapstructure:"sort_query_params"`
+	StripTrackingParams      bool     `mapstructure:"strip_tracking_params"`
+	TrackingParams           []string `mapstructure:"tracking_params"`
+	AllowedSchemes           []string `mapstructure:"allowed_schemes"`
+	AllowPrivateDestinations bool     `mapstructure:"allow_private_destinations"`
+	AllowlistFile            string   `mapstructure:"allowlist_file"`
+	DenylistFile             string   `mapstructure:"denylist_file"`
+	PolicyReloadSec          int      `mapstructure:"policy_reload_sec"`
+	// Scanner selects the malicious URL scanner: none, local or http
+	Scanner              string `mapstructure:"scanner"`
+	ScannerBlocklistFile string `mapstructure:"scanner_blocklist_file"`
+	ScannerEndpoint      string `mapstructure:"scanner_endpoint"`
+	ScannerToken         string `mapstructure:"scanner_token"`
+	ScannerTimeoutMS     int    `mapstructure:"scanner_timeout_ms"`
+	ScanOnRedirect       bool   `mapstructure:"scan_on_redirect"`
 }
 
 type AuthConfig struct {
+	// Token is an optional bootstrap admin token, used to create the first API keys
 	Token string `mapstructure:"token"`
+	// JWKSURL or JWKSFile enable JWT bearer tokens signed with the keys of the set
+	JWKSURL        string `mapstructure:"jwks_url"`
+	JWKSFile       string `mapstructure:"jwks_file"`
+	JWKSRefreshSec int    `mapstructure:"jwks_refresh_sec"`
+	JWKSTimeoutMS  int    `mapstructure:"jwks_timeout_ms"`
+	JWTIssuer      string `mapstructure:"jwt_issuer"`
+	JWTAudience    string `mapstructure:"jwt_audience"`
+	JWTLeewaySec   int    `mapstructure:"jwt_leeway_sec"`
+	// JWTWorkspaceClaim names the claim holding the workspace of the token subject
+	JWTWorkspaceClaim string `mapstructure:"jwt_workspace_claim"`
+	// JWTAdminSubjects are the token subjects that become instance admins when
+	// their token has the admin scope
+	JWTAdminSubjects []string `mapstructure:"jwt_admin_subjects"`
+}
+
+// defaultReportRateWindowSec is the abuse report rate limit window
+const defaultReportRateWindowSec = 3600
+
+// AbuseConfig limits how many abuse reports a client can send
+type AbuseConfig struct {
+	ReportRateLimit     int `mapstructure:"report_rate_limit"`
+	ReportRateWindowSec int `mapstructure:"report_rate_window_sec"`
+}
+
+type AnalyticsConfig struct {
+	ClickQueueSize       int    `mapstructure:"click_queue_size"`
+	ClickBatchSize       int    `mapstructure:"click_batch_size"`
+	ClickFlushIntervalMS int    `mapstructure:"click_flush_interval_ms"`
+	ClickWorkers         int    `mapstructure:"click_workers"`
+	RollupIntervalSec    int    `mapstructure:"rollup_interval_sec"`
+	GeoIPDatabasePath    string `mapstructure:"geoip_database_path"`
+	VisitorsTTLDays      int    `mapstructure:"visitors_ttl_days"`
 }
 
 func Load() *Config {
@@ -59,7 +131,34 @@ func Load() *Config {
 	viper.SetDefault("REDIS_PORT", 6379)
 	viper.SetDefault("CACHE_TTL", 3600)
 	viper.SetDefault("SHORT_CODE_LENGTH", 6)
-	viper.SetDefault("AUTH_TOKEN", "your-secret-token")
+	viper.SetDefault("ALIAS_MIN_LENGTH", 3)
+	viper.SetDefault("ALIAS_MAX_LENGTH", 32)
+	viper.SetDefault("BATCH_MAX_SIZE", 500)
+	viper.SetDefault("CODE_STRATEGY", "random")
+	viper.SetDefault("SHORT_CODE_MAX_LENGTH", 10)
+	viper.SetDefault("CODE_COLLISION_THRESHOLD", 0.1)
+	viper.SetDefault("DEDUPLICATE_URLS", false)
+	viper.SetDefault("SORT_QUERY_PARAMS", false)
+	viper.SetDefault("STRIP_TRACKING_PARAMS", true)
+	viper.SetDefault("TRACKING_PARAMS", "utm_*,fbclid,gclid")
+	viper.SetDefault("ALLOWED_SCHEMES", "http,https")
+	viper.SetDefault("ALLOW_PRIVATE_DESTINATIONS", false)
+	viper.SetDefault("POLICY_RELOAD_SEC", 30)
+	viper.SetDefault("URL_SCANNER", "none")
+	viper.SetDefault("URL_SCANNER_TIMEOUT_MS", 2000)
+	viper.SetDefault("URL_SCANNER_ON_REDIRECT", false)
+	viper.SetDefault("AUTH_JWKS_REFRESH_SEC", 300)
+	viper.SetDefault("AUTH_JWKS_TIMEOUT_MS", 5000)
+	viper.SetDefault("AUTH_JWT_LEEWAY_SEC", 60)
+	viper.SetDefault("AUTH_JWT_WORKSPACE_CLAIM", "workspace")
+	viper.SetDefault("REPORT_RATE_LIMIT", 5)
+	viper.SetDefault("REPORT_RATE_WINDOW_SEC", defaultReportRateWindowSec)
+	viper.SetDefault("CLICK_QUEUE_SIZE", 10000)
+	viper.SetDefault("CLICK_BATCH_SIZE", 500)
+	viper.SetDefault("CLICK_FLUSH_INTERVAL_MS", 1000)
+	viper.SetDefault("CLICK_WORKERS", 2)
+	viper.SetDefault("ROLLUP_INTERVAL_SEC", 60)
+	viper.SetDefault("VISITORS_TTL_DAYS", 90)
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -72,8 +171,9 @@ func Load() *Config {
 
 	config := &Config{
 		Server: ServerConfig{
-			Port: viper.GetString("PORT"),
-			Env:  viper.GetString("ENV"),
+			Port:           viper.GetString("PORT"),
+			Env:            viper.GetString("ENV"),
+			TrustedProxies: splitList(viper.GetString("TRUSTED_PROXIES")),
 		},
 		Database: DatabaseConfig{
 			Host:     viper.GetString("DB_HOST"),
@@ -90,14 +190,79 @@ func Load() *Config {
 			DB:       viper.GetInt("REDIS_DB"),
 		},
 		App: AppConfig{
-			BaseURL:         viper.GetString("BASE_URL"),
-			CacheTTL:        viper.GetInt("CACHE_TTL"),
-			ShortCodeLength: viper.GetInt("SHORT_CODE_LENGTH"),
+			BaseURL:                viper.GetString("BASE_URL"),
+			CacheTTL:               viper.GetInt("CACHE_TTL"),
+			ShortCodeLength:        viper.GetInt("SHORT_CODE_LENGTH"),
+			AliasMinLength:         viper.GetInt("ALIAS_MIN_LENGTH"),
+			AliasMaxLength:         viper.GetInt("ALIAS_MAX_LENGTH"),
+			ReservedAliases:        splitList(viper.GetString("RESERVED_ALIASES")),
+			IPHashSalt:             viper.GetString("IP_HASH_SALT"),
+			BatchMaxSize:           viper.GetInt("BATCH_MAX_SIZE"),
+			CodeStrategy:           viper.GetString("CODE_STRATEGY"),
+			CodeObfuscationKey:     viper.GetString("CODE_OBFUSCATION_KEY"),
+			ShortCodeMaxLength:     viper.GetInt("SHORT_CODE_MAX_LENGTH"),
+			CodeCollisionThreshold: viper.GetFloat64("CODE_COLLISION_THRESHOLD"),
+			DeduplicateURLs:        viper.GetBool("DEDUPLICATE_URLS"),
 		},
 		Auth: AuthConfig{
-			Token: viper.GetString("AUTH_TOKEN"),
+			Token:             viper.GetString("AUTH_TOKEN"),
+			JWKSURL:           viper.GetString("AUTH_JWKS_URL"),
+			JWKSFile:          viper.GetString("AUTH_JWKS_FILE"),
+			JWKSRefreshSec:    viper.GetInt("AUTH_JWKS_REFRESH_SEC"),
+			JWKSTimeoutMS:     viper.GetInt("AUTH_JWKS_TIMEOUT_MS"),
+			JWTIssuer:         viper.GetString("AUTH_JWT_ISSUER"),
+			JWTAudience:       viper.GetString("AUTH_JWT_AUDIENCE"),
+			JWTLeewaySec:      viper.GetInt("AUTH_JWT_LEEWAY_SEC"),
+			JWTWorkspaceClaim: viper.GetString("AUTH_JWT_WORKSPACE_CLAIM"),
+			JWTAdminSubjects:  splitList(viper.GetString("AUTH_JWT_ADMIN_SUBJECTS")),
+		},
+		Analytics: AnalyticsConfig{
+			ClickQueueSize:       viper.GetInt("CLICK_QUEUE_SIZE"),
+			ClickBatchSize:       viper.GetInt("CLICK_BATCH_SIZE"),
+			ClickFlushIntervalMS: viper.GetInt("CLICK_FLUSH_INTERVAL_MS"),
+			ClickWorkers:         viper.GetInt("CLICK_WORKERS"),
+			RollupIntervalSec:    viper.GetInt("ROLLUP_INTERVAL_SEC"),
+			GeoIPDatabasePath:    viper.GetString("GEOIP_DB_PATH"),
+			VisitorsTTLDays:      viper.GetInt("VISITORS_TTL_DAYS"),
 		},
+		URLs: URLConfig{
+			SortQueryParams:          viper.GetBool("SORT_QUERY_PARAMS"),
+			StripTrackingParams:      viper.GetBool("STRIP_TRACKING_PARAMS"),
+			TrackingParams:           splitList(viper.GetString("TRACKING_PARAMS")),
+			AllowedSchemes:           splitList(viper.GetString("ALLOWED_SCHEMES")),
+			AllowPrivateDestinations: viper.GetBool("ALLOW_PRIVATE_DESTINATIONS"),
+			AllowlistFile:            viper.GetString("DESTINATION_ALLOWLIST_FILE"),
+			DenylistFile:             viper.GetString("DESTINATION_DENYLIST_FILE"),
+			PolicyReloadSec:          viper.GetInt("POLICY_RELOAD_SEC"),
+			Scanner:                  viper.GetString("URL_SCANNER"),
+			ScannerBlocklistFile:     viper.GetString("URL_SCANNER_BLOCKLIST_FILE"),
+			ScannerEndpoint:          viper.GetString("URL_SCANNER_ENDPOINT"),
+			ScannerToken:             viper.GetString("URL_SCANNER_TOKEN"),
+			ScannerTimeoutMS:         viper.GetInt("URL_SCANNER_TIMEOUT_MS"),
+			ScanOnRedirect:           viper.GetBool("URL_SCANNER_ON_REDIRECT"),
+		},
+		Abuse: AbuseConfig{
+			ReportRateLimit:     viper.GetInt("REPORT_RATE_LIMIT"),
+			ReportRateWindowSec: viper.GetInt("REPORT_RATE_WINDOW_SEC"),
+		},
+	}
+
+	// A zero TTL deletes the counter, which would silently disable the limit
+	if config.Abuse.ReportRateWindowSec <= 0 {
+		log.Printf("Warning: REPORT_RATE_WINDOW_SEC must be positive, using %d", defaultReportRateWindowSec)
+		config.Abuse.ReportRateWindowSec = defaultReportRateWindowSec
 	}
 
 	return config
 }
+
+// splitList parses a comma separated environment value into a trimmed slice
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
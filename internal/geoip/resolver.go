@@ -0,0 +1,87 @@
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Location is the resolved position of a client IP
+type Location struct {
+	Country string
+	City    string
+}
+
+// Resolver looks up the location of client IPs
+type Resolver interface {
+	Lookup(ip string) Location
+	Close() error
+}
+
+// mmdbRecord is the subset of the GeoIP2/GeoLite2 City schema we read
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+type mmdbResolver struct {
+	reader *maxminddb.Reader
+}
+
+// NewResolver opens the local MaxMind database configured in GEOIP_DB_PATH.
+// Lookups never leave the process. When no database is configured or it can't
+// be opened, a resolver returning empty locations is used instead.
+func NewResolver(cfg *config.Config) Resolver {
+	path := cfg.Analytics.GeoIPDatabasePath
+	if path == "" {
+		return noopResolver{}
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		logger.Warn("GeoIP database unavailable, locations will not be recorded", zap.String("path", path), zap.Error(err))
+		return noopResolver{}
+	}
+
+	logger.Info("GeoIP database loaded", zap.String("path", path), zap.String("type", reader.Metadata.DatabaseType))
+	return &mmdbResolver{reader: reader}
+}
+
+func (r *mmdbResolver) Lookup(ip string) Location {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}
+	}
+
+	var record mmdbRecord
+	if err := r.reader.Lookup(parsed, &record); err != nil {
+		logger.Debug("GeoIP lookup failed", zap.Error(err))
+		return Location{}
+	}
+
+	return Location{
+		Country: record.Country.ISOCode,
+		City:    record.City.Names["en"],
+	}
+}
+
+func (r *mmdbResolver) Close() error {
+	return r.reader.Close()
+}
+
+type noopResolver struct{}
+
+func (noopResolver) Lookup(string) Location {
+	return Location{}
+}
+
+func (noopResolver) Close() error {
+	return nil
+}
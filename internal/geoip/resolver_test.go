@@ -0,0 +1,29 @@
+package geoip
+
+import (
+	"testing"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResolver_WithoutDatabase(t *testing.T) {
+	// Setup
+	assert.NoError(t, logger.Init("test"))
+
+	tests := []string{"", "/nonexistent/GeoLite2-City.mmdb"}
+
+	for _, path := range tests {
+		cfg := &config.Config{
+			Analytics: config.AnalyticsConfig{GeoIPDatabasePath: path},
+		}
+
+		// Execute
+		resolver := NewResolver(cfg)
+
+		// Assert - a missing database degrades to empty locations
+		assert.Equal(t, Location{}, resolver.Lookup("203.0.113.7"), path)
+		assert.NoError(t, resolver.Close())
+	}
+}
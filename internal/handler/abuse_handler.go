@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/service"
+	"go.uber.org/zap"
+)
+
+type AbuseHandler struct {
+	abuseService service.AbuseService
+}
+
+func NewAbuseHandler(abuseService service.AbuseService) *AbuseHandler {
+	return &AbuseHandler{abuseService: abuseService}
+}
+
+// ReportShortURL records an abuse report for a short URL
+// @Summary Report abuse
+// @Description Report a short URL whose destination is phishing, malware or otherwise abusive. Reports are rate limited per client IP.
+// @Tags abuse
+// @Accept json
+// @Produce json
+// @Param code path string true "Short code"
+// @Param request body model.CreateAbuseReportRequest true "Report reason and optional contact"
+// @Success 201 {object} model.CreateAbuseReportResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/report/{code} [post]
+func (h *AbuseHandler) ReportShortURL(c *gin.Context) {
+	shortCode := c.Param("code")
+
+	var req model.CreateAbuseReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warn("Invalid abuse report body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+		return
+	}
+
+	response, err := h.abuseService.ReportShortURL(shortCode, &req, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, service.ErrReportRateLimited) {
+			logger.Warn("Abuse report rate limited", zap.String("short_code", shortCode))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Çok fazla bildirim gönderildi, lütfen daha sonra tekrar deneyin"})
+			return
+		}
+		switch err.Error() {
+		case "reason is required":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bildirim nedeni gereklidir"})
+			return
+		case "short URL not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kısa URL bulunamadı"})
+			return
+		}
+		logger.Error("Failed to record abuse report", zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bildirim kaydedilemedi"})
+		return
+	}
+
+	logger.Info("Abuse report recorded", zap.String("short_code", shortCode), zap.Uint("report_id", response.ID))
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListReports lists abuse reports for moderation
+// @Summary List abuse reports
+// @Description List abuse reports newest first (requires Bearer token). Pass next_cursor from the previous page as cursor to continue.
+// @Tags abuse
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param status query string false "Report status" Enums(open, resolved)
+// @Param code query string false "Short code"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Page size (max 200)" default(50)
+// @Success 200 {object} model.ListAbuseReportsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/admin/reports [get]
+func (h *AbuseHandler) ListReports(c *gin.Context) {
+	var req model.ListAbuseReportsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Warn("Invalid report list query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sorgu parametreleri"})
+		return
+	}
+
+	response, err := h.abuseService.ListReports(&req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid status":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz status, 'open' veya 'resolved' olmalıdır"})
+			return
+		case "invalid cursor":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz cursor"})
+			return
+		}
+		logger.Error("Failed to list abuse reports", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bildirimler listelenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DisableShortURL disables a short URL without deleting it
+// @Summary Disable short URL
+// @Description Quarantine a short URL so redirects show a warning page instead of the destination, and resolve its open reports (requires Bearer token)
+// @Tags abuse
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param code path string true "Short code"
+// @Param request body model.ModerateShortURLRequest false "Optional reason"
+// @Success 200 {object} model.ModerationResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/admin/urls/{code}/disable [post]
+func (h *AbuseHandler) DisableShortURL(c *gin.Context) {
+	shortCode := c.Param("code")
+
+	var req model.ModerateShortURLRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Warn("Invalid disable request body", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+			return
+		}
+	}
+
+	response, err := h.abuseService.DisableShortURL(shortCode, req.Reason)
+	if err != nil {
+		h.moderationError(c, shortCode, err)
+		return
+	}
+
+	logger.Info("Short URL disabled", zap.String("short_code", shortCode), zap.Int64("resolved_reports", response.ResolvedReports))
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreShortURL lifts the quarantine of a short URL
+// @Summary Restore short URL
+// @Description Lift a quarantine set by a moderator or the URL scanner so redirects work again, and resolve its open reports (requires Bearer token)
+// @Tags abuse
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param code path string true "Short code"
+// @Success 200 {object} model.ModerationResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/admin/urls/{code}/restore [post]
+func (h *AbuseHandler) RestoreShortURL(c *gin.Context) {
+	shortCode := c.Param("code")
+
+	response, err := h.abuseService.RestoreShortURL(shortCode)
+	if err != nil {
+		h.moderationError(c, shortCode, err)
+		return
+	}
+
+	logger.Info("Short URL restored", zap.String("short_code", shortCode), zap.Int64("resolved_reports", response.ResolvedReports))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AbuseHandler) moderationError(c *gin.Context, shortCode string, err error) {
+	if err.Error() == "short URL not found" {
+		logger.Warn("Short URL not found for moderation", zap.String("short_code", shortCode))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kısa URL bulunamadı"})
+		return
+	}
+	logger.Error("Failed to moderate short URL", zap.Error(err), zap.String("short_code", shortCode))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Kısa URL durumu güncellenemedi"})
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/service"
+	"go.uber.org/zap"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey issues a new API key
+// @Summary Create API key
+// @Description Create an API key (requires Bearer token). The key is only returned in this response, store it safely. Instance admins can create keys in any workspace and admin keys; workspace admins create keys for members of their workspace, others for themselves.
+// @Tags keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.CreateAPIKeyRequest true "Key name, optional workspace and member, admin flag, scopes and expiry"
+// @Success 201 {object} model.CreateAPIKeyResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warn("Invalid api key request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+		return
+	}
+
+	principal := currentPrincipal(c)
+	response, err := h.apiKeyService.CreateKey(principal, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			logger.Warn("API key creation denied", zap.String("workspace_id", principal.WorkspaceID))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yetkiniz yok"})
+			return
+		}
+		switch err.Error() {
+		case "name is required":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Anahtar adı gereklidir"})
+			return
+		case "invalid expiry":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Son kullanma tarihi gelecekte olmalıdır"})
+			return
+		case "member_id is required":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Üye ID gereklidir"})
+			return
+		case "invalid scopes":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kapsam, links:write, links:read, stats:read veya admin olmalıdır; admin anahtarlar admin kapsamını içermelidir"})
+			return
+		case "member not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Çalışma alanı üyesi bulunamadı"})
+			return
+		}
+		logger.Error("Failed to create api key", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı oluşturulamadı"})
+		return
+	}
+
+	logger.Info("API key created", zap.Uint("key_id", response.ID), zap.String("workspace_id", response.WorkspaceID))
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListAPIKeys lists API keys
+// @Summary List API keys
+// @Description List API keys without their secrets (requires Bearer token). Instance admins see every key, workspace admins their workspace's keys, others their own keys.
+// @Tags keys
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} model.ListAPIKeysResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	response, err := h.apiKeyService.ListKeys(currentPrincipal(c))
+	if err != nil {
+		logger.Error("Failed to list api keys", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarları listelenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeAPIKey revokes an API key
+// @Summary Revoke API key
+// @Description Revoke an API key so it can no longer authenticate (requires Bearer token with every scope of the key). Revoked keys stay listed.
+// @Tags keys
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "API key ID"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz anahtar ID"})
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(currentPrincipal(c), uint(id)); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			logger.Warn("API key revocation denied", zap.Uint64("key_id", id))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yetkiniz yok"})
+			return
+		}
+		if err.Error() == "api key not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API anahtarı bulunamadı"})
+			return
+		}
+		logger.Error("Failed to revoke api key", zap.Error(err), zap.Uint64("key_id", id))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı iptal edilemedi"})
+		return
+	}
+
+	logger.Info("API key revoked", zap.Uint64("key_id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "API anahtarı iptal edildi"})
+}
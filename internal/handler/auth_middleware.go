@@ -1,17 +1,25 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/auth"
 	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/service"
 	"go.uber.org/zap"
 )
 
-// AuthMiddleware validates the Bearer token for protected endpoints
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// principalKey is the gin context key of the authenticated principal
+const principalKey = "principal"
+
+// AuthMiddleware validates the Bearer token for protected endpoints and stores
+// the authenticated principal in the context. Tokens shaped like a JWT go to
+// jwtAuthenticator when it is set, everything else is treated as an API key.
+func AuthMiddleware(apiKeyService service.APIKeyService, jwtAuthenticator service.Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -30,15 +38,96 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
-		if token != cfg.Auth.Token {
-			logger.Warn("Invalid token provided", zap.String("provided_token", token))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Geçersiz token"})
+		var authenticator service.Authenticator = apiKeyService
+		if jwtAuthenticator != nil && auth.IsJWT(parts[1]) {
+			authenticator = jwtAuthenticator
+		}
+
+		principal, err := authenticator.Authenticate(parts[1])
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidCredentials) {
+				logger.Warn("Invalid token provided", zap.Error(err))
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Geçersiz token"})
+			} else {
+				logger.Error("Failed to authenticate token", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Kimlik doğrulanamadı"})
+			}
+			c.Abort()
+			return
+		}
+
+		logger.Debug("Token validated successfully", zap.String("workspace_id", principal.WorkspaceID), zap.String("role", principal.Role), zap.Uint("key_id", principal.KeyID))
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// AdminMiddleware only lets admin principals through. It must run after AuthMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := currentPrincipal(c)
+		if principal == nil || !principal.Admin {
+			logger.Warn("Admin access denied")
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yetkiniz yok"})
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		logger.Debug("Token validated successfully")
+// RoleMiddleware only lets principals with at least the given workspace role
+// through. Instance admins pass every role check. It must run after AuthMiddleware.
+func RoleMiddleware(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := currentPrincipal(c)
+		if !service.HasRole(principal, role) {
+			logger.Warn("Role check failed", zap.String("required_role", role))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yetkiniz yok"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// WorkspaceMiddleware only lets principals act on the workspace in the :id path
+// parameter when it is their own. Role checks look at the role in the caller's
+// workspace, so they must not apply to another one. Instance admins pass. It
+// must run after AuthMiddleware.
+func WorkspaceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := currentPrincipal(c)
+		if principal == nil || (!principal.Admin && principal.WorkspaceID != c.Param("id")) {
+			logger.Warn("Workspace access denied", zap.String("workspace_id", c.Param("id")))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yetkiniz yok"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ScopeMiddleware only lets principals whose credential has the given scope
+// through. Unlike roles, scopes also bind instance admins. It must run after AuthMiddleware.
+func ScopeMiddleware(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !service.HasScope(currentPrincipal(c), scope) {
+			logger.Warn("Scope check failed", zap.String("required_scope", scope))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için anahtarın " + scope + " kapsamına sahip olması gerekir"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentPrincipal returns the principal stored by AuthMiddleware, or nil on public routes
+func currentPrincipal(c *gin.Context) *model.Principal {
+	if value, ok := c.Get(principalKey); ok {
+		if principal, ok := value.(*model.Principal); ok {
+			return principal
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/service"
+	"go.uber.org/zap"
+)
+
+// exportContentTypes maps the supported export formats to their content types
+var exportContentTypes = map[string]string{
+	model.FormatCSV:    "text/csv; charset=utf-8",
+	model.FormatNDJSON: "application/x-ndjson",
+}
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportShortURLs streams all matching short URLs
+// @Summary Export short URLs
+// @Description Stream all matching short URLs as CSV or NDJSON, oldest first (requires Bearer token). The columns match the import format.
+// @Tags export
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "Output format" Enums(csv, ndjson) default(csv)
+// @Param domain query string false "Destination domain substring"
+// @Param status query string false "Expiry or quarantine status" Enums(active, expired, quarantined)
+// @Param created_from query string false "Created at or after (RFC3339)"
+// @Param created_to query string false "Created before (RFC3339)"
+// @Success 200 {string} string "Streamed rows"
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/export/urls [get]
+func (h *ExportHandler) ExportShortURLs(c *gin.Context) {
+	var req model.ExportShortURLsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Warn("Invalid export query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sorgu parametreleri"})
+		return
+	}
+	if !startExport(c, &req.Format, "urls") {
+		return
+	}
+
+	filter := &model.ShortURLFilter{
+		Domain:      req.Domain,
+		Status:      req.Status,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+	}
+	err := h.exportService.ExportShortURLs(c.Writer, req.Format, filter)
+	finishExport(c, err, "urls")
+}
+
+// ExportClicks streams raw click events
+// @Summary Export clicks
+// @Description Stream raw click events as CSV or NDJSON in the order they were stored (requires Bearer token)
+// @Tags export
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "Output format" Enums(csv, ndjson) default(csv)
+// @Param code query string false "Only clicks of this short code"
+// @Param from query string false "Clicked at or after (RFC3339)"
+// @Param to query string false "Clicked before (RFC3339)"
+// @Success 200 {string} string "Streamed rows"
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/export/clicks [get]
+func (h *ExportHandler) ExportClicks(c *gin.Context) {
+	var req model.ExportClicksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Warn("Invalid export query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sorgu parametreleri"})
+		return
+	}
+	if !startExport(c, &req.Format, "clicks") {
+		return
+	}
+
+	filter := &model.ClickFilter{
+		ShortCode: req.Code,
+		From:      req.From,
+		To:        req.To,
+	}
+	err := h.exportService.ExportClicks(c.Writer, req.Format, filter)
+	finishExport(c, err, "clicks")
+}
+
+// startExport validates the format and sets the download headers
+func startExport(c *gin.Context, format *string, name string) bool {
+	if *format == "" {
+		*format = model.FormatCSV
+	}
+	contentType, ok := exportContentTypes[*format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz format, 'csv' veya 'ndjson' olmalıdır"})
+		return false
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, *format))
+	return true
+}
+
+// finishExport reports export errors. Once rows were sent the status can't
+// change anymore, so the response is cut short and the client sees a truncated body.
+func finishExport(c *gin.Context, err error, name string) {
+	if err == nil {
+		return
+	}
+
+	if c.Writer.Written() {
+		logger.Error("Export aborted while streaming", zap.String("export", name), zap.Error(err))
+		c.Abort()
+		return
+	}
+
+	c.Writer.Header().Del("Content-Disposition")
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err.Error() == "invalid status" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz status, 'active', 'expired' veya 'quarantined' olmalıdır"})
+		return
+	}
+	logger.Error("Failed to export", zap.String("export", name), zap.Error(err))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Dışa aktarma başarısız oldu"})
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/service"
+	"go.uber.org/zap"
+)
+
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+func NewImportHandler(importService service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportShortURLs imports links from another shortener keeping their codes
+// @Summary Import short URLs
+// @Description Import links as CSV (header row with code, destination, expires_at, created_at) or NDJSON (one object per line with the same fields). The request body is streamed, existing codes are reported as conflicts and invalid rows are skipped (requires admin Bearer token). Links belong to workspace_id, or to the caller's workspace when omitted.
+// @Tags urls
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "csv or ndjson, detected from Content-Type when omitted"
+// @Param workspace_id query string false "Workspace of the imported links"
+// @Success 200 {object} model.ImportReport
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/import [post]
+func (h *ImportHandler) ImportShortURLs(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = formatFromContentType(c.ContentType())
+	}
+
+	workspaceID := c.Query("workspace_id")
+	if workspaceID == "" {
+		workspaceID = currentPrincipal(c).WorkspaceID
+	}
+
+	report, err := h.importService.Import(c.Request.Body, format, workspaceID)
+	if err != nil {
+		switch err.Error() {
+		case "invalid format":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz içe aktarma formatı, csv veya ndjson kullanın"})
+			return
+		case "invalid header":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV başlık satırı code ve destination sütunlarını içermelidir"})
+			return
+		}
+		logger.Error("Failed to import short URLs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İçe aktarma tamamlanamadı", "report": report})
+		return
+	}
+
+	logger.Info("Short URL import finished",
+		zap.Int("imported", report.Imported),
+		zap.Int("conflicts", report.Conflicts),
+		zap.Int("invalid", report.Invalid),
+	)
+	c.JSON(http.StatusOK, report)
+}
+
+// formatFromContentType maps a request or file content type to an import format
+func formatFromContentType(contentType string) string {
+	switch strings.ToLower(contentType) {
+	case "text/csv", "application/csv":
+		return model.FormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return model.FormatNDJSON
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/service"
+)
+
+type MetricsHandler struct {
+	urlService       service.URLService
+	analyticsService service.AnalyticsService
+}
+
+func NewMetricsHandler(urlService service.URLService, analyticsService service.AnalyticsService) *MetricsHandler {
+	return &MetricsHandler{
+		urlService:       urlService,
+		analyticsService: analyticsService,
+	}
+}
+
+// GetMetrics returns internal service metrics
+// @Summary Service metrics
+// @Description Returns click ingestion queue counters such as enqueued, dropped and flushed clicks, and short code generation counters such as the current code length and collision rate (requires Bearer token)
+// @Tags metrics
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} model.MetricsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Router /api/v1/metrics [get]
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, model.MetricsResponse{
+		ClickPipeline: h.analyticsService.GetPipelineMetrics(),
+		CodeGenerator: h.urlService.GetCodeGeneratorMetrics(),
+	})
+}
@@ -1,15 +1,20 @@
 package handler
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
-	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
 	"github.com/shortener/internal/service"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(urlService service.URLService, cfg *config.Config) *gin.Engine {
-	router := gin.Default()
+func SetupRoutes(trustedProxies []string, urlService service.URLService, analyticsService service.AnalyticsService, importService service.ImportService, exportService service.ExportService, abuseService service.AbuseService, apiKeyService service.APIKeyService, jwtAuthenticator service.Authenticator, workspaceService service.WorkspaceService) (*gin.Engine, error) {
+	router, err := newRouter(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
 
 	// Middleware
 	router.Use(gin.Logger())
@@ -18,7 +23,7 @@ func SetupRoutes(urlService service.URLService, cfg *config.Config) *gin.Engine
 	// CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if c.Request.Method == "OPTIONS" {
@@ -30,7 +35,24 @@ func SetupRoutes(urlService service.URLService, cfg *config.Config) *gin.Engine
 	})
 
 	// Initialize handlers
-	urlHandler := NewURLHandler(urlService)
+	urlHandler := NewURLHandler(urlService, analyticsService)
+	metricsHandler := NewMetricsHandler(urlService, analyticsService)
+	importHandler := NewImportHandler(importService)
+	exportHandler := NewExportHandler(exportService)
+	abuseHandler := NewAbuseHandler(abuseService)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService)
+	workspaceHandler := NewWorkspaceHandler(workspaceService)
+
+	auth := AuthMiddleware(apiKeyService, jwtAuthenticator)
+	admin := AdminMiddleware()
+	viewer := RoleMiddleware(model.RoleViewer)
+	editor := RoleMiddleware(model.RoleEditor)
+	workspaceAdmin := RoleMiddleware(model.RoleAdmin)
+	linksWrite := ScopeMiddleware(model.ScopeLinksWrite)
+	linksRead := ScopeMiddleware(model.ScopeLinksRead)
+	statsRead := ScopeMiddleware(model.ScopeStatsRead)
+	adminScope := ScopeMiddleware(model.ScopeAdmin)
+	ownWorkspace := WorkspaceMiddleware()
 
 	// Health check endpoint
 	router.GET("/healthz", urlHandler.HealthCheck)
@@ -38,10 +60,41 @@ func SetupRoutes(urlService service.URLService, cfg *config.Config) *gin.Engine
 	// API routes
 	api := router.Group("/api/v1")
 	{
-		// Protected route - requires auth token
-		api.POST("/shorten", AuthMiddleware(cfg), urlHandler.CreateShortURL)
+		// Protected route - requires the links:write scope and the editor role
+		api.POST("/shorten", auth, linksWrite, editor, urlHandler.CreateShortURL)
+		api.POST("/shorten/batch", auth, linksWrite, editor, urlHandler.CreateShortURLsBatch)
+		// Protected routes - stats of links in the caller's workspace
+		api.GET("/stats/:code", auth, statsRead, viewer, urlHandler.GetURLStats)
+		api.GET("/stats/:code/timeseries", auth, statsRead, viewer, urlHandler.GetURLTimeSeries)
+		api.GET("/stats/:code/breakdown", auth, statsRead, viewer, urlHandler.GetURLBreakdown)
 		// Public route - no auth required
-		api.GET("/stats/:code", urlHandler.GetURLStats)
+		api.POST("/report/:code", abuseHandler.ReportShortURL)
+		// Protected routes - manage existing short URLs
+		api.GET("/urls", auth, linksRead, viewer, urlHandler.ListShortURLs)
+		api.PATCH("/urls/:code", auth, linksWrite, editor, urlHandler.UpdateShortURL)
+		api.DELETE("/urls/:code", auth, linksWrite, editor, urlHandler.DeleteShortURL)
+		// Protected routes - API keys of the caller, or of the workspace for workspace
+		// admins. Any scope works, new keys can't get more scopes than the caller's.
+		api.POST("/keys", auth, viewer, apiKeyHandler.CreateAPIKey)
+		api.GET("/keys", auth, viewer, apiKeyHandler.ListAPIKeys)
+		api.DELETE("/keys/:id", auth, viewer, apiKeyHandler.RevokeAPIKey)
+		// Protected routes - workspaces and their members
+		api.POST("/workspaces", auth, adminScope, admin, workspaceHandler.CreateWorkspace)
+		api.GET("/workspaces", auth, viewer, workspaceHandler.ListWorkspaces)
+		api.GET("/workspaces/:id/members", auth, ownWorkspace, viewer, workspaceHandler.ListMembers)
+		api.POST("/workspaces/:id/members", auth, ownWorkspace, adminScope, workspaceAdmin, workspaceHandler.AddMember)
+		api.PATCH("/workspaces/:id/members/:member", auth, ownWorkspace, adminScope, workspaceAdmin, workspaceHandler.UpdateMember)
+		api.DELETE("/workspaces/:id/members/:member", auth, ownWorkspace, adminScope, workspaceAdmin, workspaceHandler.RemoveMember)
+		// Admin routes - span every workspace's links
+		api.POST("/import", auth, adminScope, admin, importHandler.ImportShortURLs)
+		api.GET("/export/urls", auth, adminScope, admin, exportHandler.ExportShortURLs)
+		api.GET("/export/clicks", auth, adminScope, admin, exportHandler.ExportClicks)
+		// Admin routes - abuse moderation
+		api.GET("/admin/reports", auth, adminScope, admin, abuseHandler.ListReports)
+		api.POST("/admin/urls/:code/disable", auth, adminScope, admin, abuseHandler.DisableShortURL)
+		api.POST("/admin/urls/:code/restore", auth, adminScope, admin, abuseHandler.RestoreShortURL)
+		// Admin route - internal metrics
+		api.GET("/metrics", auth, adminScope, admin, metricsHandler.GetMetrics)
 	}
 
 	// Redirect route (short URL resolution)
@@ -50,5 +103,15 @@ func SetupRoutes(urlService service.URLService, cfg *config.Config) *gin.Engine
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	return router
+	return router, nil
+}
+
+// newRouter creates the engine. Client IPs feed the report rate limit and the
+// visitor counts, so X-Forwarded-For is only read from the trusted proxies.
+func newRouter(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	return router, nil
 }
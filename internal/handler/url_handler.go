@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/shortener/internal/logger"
@@ -11,28 +14,34 @@ import (
 )
 
 type URLHandler struct {
-	urlService service.URLService
+	urlService       service.URLService
+	analyticsService service.AnalyticsService
 }
 
-func NewURLHandler(urlService service.URLService) *URLHandler {
+func NewURLHandler(urlService service.URLService, analyticsService service.AnalyticsService) *URLHandler {
 	return &URLHandler{
-		urlService: urlService,
+		urlService:       urlService,
+		analyticsService: analyticsService,
 	}
 }
 
 // CreateShortURL creates a new short URL
 // @Summary Create short URL
-// @Description Create a new short URL from original URL (requires Bearer token)
+// @Description Create a new short URL from original URL (requires Bearer token with the editor role). The link belongs to the caller's workspace. An optional alias sets a custom short code.
 // @Tags urls
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer token"
-// @Param request body model.CreateShortURLRequest true "URL creation request (expires_at optional)"
+// @Param request body model.CreateShortURLRequest true "URL creation request (alias, expires_at and deduplicate optional)"
+// @Success 200 {object} model.CreateShortURLResponse "Existing short URL for the same destination and expiry (deduplicated)"
 // @Success 201 {object} model.CreateShortURLResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
 // @Router /api/v1/shorten [post]
 func (h *URLHandler) CreateShortURL(c *gin.Context) {
 	var req model.CreateShortURLRequest
@@ -42,23 +51,109 @@ func (h *URLHandler) CreateShortURL(c *gin.Context) {
 		return
 	}
 
-	response, err := h.urlService.CreateShortURL(&req)
+	response, err := h.urlService.CreateShortURL(currentPrincipal(c), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrDestinationNotAllowed) {
+			logger.Warn("Destination rejected by policy", zap.String("url", req.URL), zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bu hedef URL'e izin verilmiyor"})
+			return
+		}
+		if errors.Is(err, service.ErrCodeCollision) {
+			logger.Error("No free short code found", zap.Error(err))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kısa kod üretilemedi, lütfen tekrar deneyin"})
+			return
+		}
+		switch err.Error() {
+		case "invalid url":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz URL"})
+			return
+		case "invalid alias":
+			logger.Warn("Invalid alias", zap.String("alias", req.Alias))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz alias"})
+			return
+		case "alias is reserved":
+			logger.Warn("Reserved alias requested", zap.String("alias", req.Alias))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bu alias kullanılamaz"})
+			return
+		case "alias already in use":
+			logger.Warn("Alias already in use", zap.String("alias", req.Alias))
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu alias zaten kullanımda"})
+			return
+		}
 		logger.Error("Failed to create short URL", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kısa URL oluşturulamadı"})
 		return
 	}
 
+	if response.Quarantined {
+		logger.Warn("Short URL quarantined by URL scanner", zap.String("short_code", response.ShortCode))
+	}
+
+	if response.Deduplicated {
+		logger.Info("Existing short URL returned for duplicate destination", zap.String("short_code", response.ShortCode))
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
 	logger.Info("Short URL created successfully", zap.String("short_code", response.ShortCode))
 	c.JSON(http.StatusCreated, response)
 }
 
+// CreateShortURLsBatch creates many short URLs in one request
+// @Summary Create short URLs in bulk
+// @Description Create up to BATCH_MAX_SIZE short URLs at once (requires Bearer token with the editor role). Each item is validated on its own and reported with its index; valid items are stored in a single transaction.
+// @Tags urls
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.BatchCreateShortURLRequest true "Items to shorten"
+// @Success 200 {object} model.BatchCreateShortURLResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/shorten/batch [post]
+func (h *URLHandler) CreateShortURLsBatch(c *gin.Context) {
+	var req model.BatchCreateShortURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Invalid batch request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+		return
+	}
+
+	response, err := h.urlService.CreateShortURLs(currentPrincipal(c), req.Items)
+	if err != nil {
+		if errors.Is(err, service.ErrCodeCollision) {
+			logger.Error("No free short codes found for batch", zap.Error(err))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kısa kodlar üretilemedi, lütfen tekrar deneyin"})
+			return
+		}
+		switch err.Error() {
+		case "batch is empty":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "En az bir URL gönderilmelidir"})
+			return
+		case "batch too large":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tek istekte gönderilebilecek URL sayısı aşıldı"})
+			return
+		}
+		logger.Error("Failed to create short URLs in batch", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kısa URL'ler oluşturulamadı"})
+		return
+	}
+
+	logger.Info("Short URL batch processed", zap.Int("created", response.Created), zap.Int("failed", response.Failed))
+	c.JSON(http.StatusOK, response)
+}
+
 // RedirectToOriginalURL redirects to the original URL
 // @Summary Redirect to original URL
-// @Description Redirect to the original URL using short code
+// @Description Redirect to the original URL using short code. Links quarantined by the URL scanner show an HTML warning page instead.
 // @Tags urls
 // @Param code path string true "Short code"
 // @Success 302 "Redirect to original URL"
+// @Failure 403 "Warning page for a quarantined link"
 // @Failure 404 {object} model.ErrorResponse
 // @Failure 410 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
@@ -72,6 +167,11 @@ func (h *URLHandler) RedirectToOriginalURL(c *gin.Context) {
 
 	originalURL, err := h.urlService.GetOriginalURL(shortCode)
 	if err != nil {
+		if errors.Is(err, service.ErrURLQuarantined) {
+			logger.Warn("Quarantined short URL requested", zap.String("short_code", shortCode))
+			renderWarningPage(c, shortCode)
+			return
+		}
 		if err.Error() == "short URL not found" {
 			logger.Warn("Short URL not found", zap.String("short_code", shortCode))
 			c.JSON(http.StatusNotFound, gin.H{"error": "Kısa URL bulunamadı"})
@@ -87,16 +187,29 @@ func (h *URLHandler) RedirectToOriginalURL(c *gin.Context) {
 		return
 	}
 
+	clickInfo := &model.ClickInfo{
+		Referrer:  c.Request.Referer(),
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+	if err := h.analyticsService.RecordClick(shortCode, clickInfo); err != nil {
+		logger.Error("Failed to record click", zap.Error(err), zap.String("short_code", shortCode))
+	}
+
 	logger.Info("Redirecting to original URL", zap.String("short_code", shortCode), zap.String("original_url", originalURL))
 	c.Redirect(http.StatusFound, originalURL)
 }
 
 // GetURLStats returns statistics for a short URL
 // @Summary Get URL statistics
-// @Description Get statistics for a short URL
+// @Description Get statistics for a short URL including total clicks, unique visitors and last click time (requires Bearer token of a member of the link's workspace)
 // @Tags urls
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
 // @Param code path string true "Short code"
 // @Success 200 {object} model.URLStatsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 404 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/stats/{code} [get]
@@ -107,8 +220,13 @@ func (h *URLHandler) GetURLStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := h.urlService.GetURLStats(shortCode)
+	stats, err := h.urlService.GetURLStats(currentPrincipal(c), shortCode)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			logger.Warn("Stats access denied", zap.String("short_code", shortCode))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu kısa URL üzerinde yetkiniz yok"})
+			return
+		}
 		if err.Error() == "short URL not found" {
 			logger.Warn("Short URL not found for stats", zap.String("short_code", shortCode))
 			c.JSON(http.StatusNotFound, gin.H{"error": "Kısa URL bulunamadı"})
@@ -119,10 +237,305 @@ func (h *URLHandler) GetURLStats(c *gin.Context) {
 		return
 	}
 
+	clickStats, err := h.analyticsService.GetClickStats(shortCode)
+	if err != nil {
+		logger.Error("Failed to get click stats", zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstatistikler alınamadı"})
+		return
+	}
+	stats.TotalClicks = clickStats.TotalClicks
+	stats.UniqueVisitors = clickStats.UniqueVisitors
+	stats.UniqueVisitorsToday = clickStats.UniqueVisitorsToday
+	stats.LastClickAt = clickStats.LastClickAt
+
 	logger.Info("URL stats retrieved", zap.String("short_code", shortCode))
 	c.JSON(http.StatusOK, stats)
 }
 
+// ListShortURLs lists short URLs with cursor based pagination
+// @Summary List short URLs
+// @Description List short URLs with sorting and filters (requires Bearer token). Admins see every link, others only the links of their workspace. Pass next_cursor from the previous page as cursor to continue.
+// @Tags urls
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Page size (max 100)" default(20)
+// @Param sort query string false "Sort field" Enums(created_at, clicks, expires_at) default(created_at)
+// @Param order query string false "Sort order" Enums(asc, desc) default(desc)
+// @Param domain query string false "Destination domain substring"
+// @Param status query string false "Expiry or quarantine status" Enums(active, expired, quarantined)
+// @Param created_from query string false "Created at or after (RFC3339)"
+// @Param created_to query string false "Created before (RFC3339)"
+// @Success 200 {object} model.ListShortURLsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/urls [get]
+func (h *URLHandler) ListShortURLs(c *gin.Context) {
+	var req model.ListShortURLsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Warn("Invalid list query", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sorgu parametreleri"})
+		return
+	}
+
+	response, err := h.urlService.ListShortURLs(currentPrincipal(c), &req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid sort":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sort, 'created_at', 'clicks' veya 'expires_at' olmalıdır"})
+			return
+		case "invalid order":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz order, 'asc' veya 'desc' olmalıdır"})
+			return
+		case "invalid status":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz status, 'active', 'expired' veya 'quarantined' olmalıdır"})
+			return
+		case "invalid cursor":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz cursor"})
+			return
+		}
+		logger.Error("Failed to list short URLs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kısa URL'ler listelenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateShortURL updates the destination or expiry of a short URL
+// @Summary Update short URL
+// @Description Change the destination URL and/or expiry of an existing short URL (requires Bearer token with the editor role in the link's workspace). Set remove_expiry to drop the expiry.
+// @Tags urls
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param code path string true "Short code"
+// @Param request body model.UpdateShortURLRequest true "Fields to update"
+// @Success 200 {object} model.CreateShortURLResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/urls/{code} [patch]
+func (h *URLHandler) UpdateShortURL(c *gin.Context) {
+	shortCode := c.Param("code")
+
+	var req model.UpdateShortURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+		return
+	}
+
+	response, err := h.urlService.UpdateShortURL(currentPrincipal(c), shortCode, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			logger.Warn("Update denied", zap.String("short_code", shortCode))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu kısa URL üzerinde yetkiniz yok"})
+			return
+		}
+		if errors.Is(err, service.ErrDestinationNotAllowed) {
+			logger.Warn("Destination rejected by policy", zap.String("short_code", shortCode), zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bu hedef URL'e izin verilmiyor"})
+			return
+		}
+		switch err.Error() {
+		case "invalid url":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz URL"})
+			return
+		case "no fields to update":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Güncellenecek alan belirtilmedi"})
+			return
+		case "short URL not found":
+			logger.Warn("Short URL not found for update", zap.String("short_code", shortCode))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kısa URL bulunamadı"})
+			return
+		}
+		logger.Error("Failed to update short URL", zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kısa URL güncellenemedi"})
+		return
+	}
+
+	logger.Info("Short URL updated", zap.String("short_code", shortCode))
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteShortURL deletes a short URL
+// @Summary Delete short URL
+// @Description Soft delete a short URL and evict it from the cache (requires Bearer token with the editor role in the link's workspace)
+// @Tags urls
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param code path string true "Short code"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/urls/{code} [delete]
+func (h *URLHandler) DeleteShortURL(c *gin.Context) {
+	shortCode := c.Param("code")
+
+	if err := h.urlService.DeleteShortURL(currentPrincipal(c), shortCode); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			logger.Warn("Delete denied", zap.String("short_code", shortCode))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu kısa URL üzerinde yetkiniz yok"})
+			return
+		}
+		if err.Error() == "short URL not found" {
+			logger.Warn("Short URL not found for delete", zap.String("short_code", shortCode))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kısa URL bulunamadı"})
+			return
+		}
+		logger.Error("Failed to delete short URL", zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kısa URL silinemedi"})
+		return
+	}
+
+	logger.Info("Short URL deleted", zap.String("short_code", shortCode))
+	c.JSON(http.StatusOK, gin.H{"message": "Kısa URL silindi"})
+}
+
+// GetURLTimeSeries returns click counts per time bucket for a short URL
+// @Summary Get URL click time series
+// @Description Get click counts per hour or day bucket. Defaults to the last 30 days by day or the last 24 hours by hour. Counts come from rollups refreshed by a background aggregator. Requires Bearer token of a member of the link's workspace.
+// @Tags urls
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param code path string true "Short code"
+// @Param interval query string false "Bucket size" Enums(hour, day) default(day)
+// @Param from query string false "Start time (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "End time (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {object} model.TimeSeriesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/stats/{code}/timeseries [get]
+func (h *URLHandler) GetURLTimeSeries(c *gin.Context) {
+	shortCode := c.Param("code")
+	interval := c.DefaultQuery("interval", model.GranularityDay)
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz 'to' parametresi"})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if interval == model.GranularityHour {
+		from = to.Add(-24 * time.Hour)
+	}
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz 'from' parametresi"})
+			return
+		}
+		from = parsed
+	}
+
+	if !h.authorizeShortURL(c, shortCode) {
+		return
+	}
+
+	series, err := h.analyticsService.GetTimeSeries(shortCode, interval, from, to)
+	if err != nil {
+		switch err.Error() {
+		case "invalid interval":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz interval, 'hour' veya 'day' olmalıdır"})
+			return
+		case "invalid time range":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz zaman aralığı"})
+			return
+		case "time range too large":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Zaman aralığı çok geniş"})
+			return
+		}
+		logger.Error("Failed to get URL time series", zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstatistikler alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetURLBreakdown returns click counts grouped by a visitor dimension
+// @Summary Get URL click breakdown
+// @Description Get click counts of a short URL grouped by referrer domain, browser, operating system, device type, country or city (requires Bearer token of a member of the link's workspace)
+// @Tags urls
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param code path string true "Short code"
+// @Param dimension query string true "Breakdown dimension" Enums(referrer, browser, os, device, country, city)
+// @Param limit query int false "Maximum number of values" default(100)
+// @Success 200 {object} model.BreakdownResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/stats/{code}/breakdown [get]
+func (h *URLHandler) GetURLBreakdown(c *gin.Context) {
+	shortCode := c.Param("code")
+	dimension := c.Query("dimension")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz 'limit' parametresi"})
+		return
+	}
+
+	if !h.authorizeShortURL(c, shortCode) {
+		return
+	}
+
+	breakdown, err := h.analyticsService.GetBreakdown(shortCode, dimension, limit)
+	if err != nil {
+		if err.Error() == "invalid dimension" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz dimension, 'referrer', 'browser', 'os', 'device', 'country' veya 'city' olmalıdır"})
+			return
+		}
+		logger.Error("Failed to get URL breakdown", zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstatistikler alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// authorizeShortURL writes a 403, 404 or 500 response and returns false when the
+// short URL doesn't exist or belongs to someone else
+func (h *URLHandler) authorizeShortURL(c *gin.Context, shortCode string) bool {
+	if err := h.urlService.AuthorizeShortURL(currentPrincipal(c), shortCode); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu kısa URL üzerinde yetkiniz yok"})
+			return false
+		}
+		if err.Error() == "short URL not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kısa URL bulunamadı"})
+			return false
+		}
+		logger.Error("Failed to get short URL", zap.Error(err), zap.String("short_code", shortCode))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstatistikler alınamadı"})
+		return false
+	}
+	return true
+}
+
 // HealthCheck returns service health status
 // @Summary Health check
 // @Description Returns the health status of the service
@@ -137,3 +550,11 @@ func (h *URLHandler) HealthCheck(c *gin.Context) {
 		"version": "1.0.0",
 	})
 }
+
+// parseTimeParam accepts RFC3339 timestamps or plain YYYY-MM-DD dates
+func parseTimeParam(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// warningPage is shown instead of redirecting to a quarantined destination.
+// The destination itself is left out so the page can't be used to reach it.
+var warningPage = template.Must(template.New("warning").Parse(`<!DOCTYPE html>
+<html lang="tr">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex, nofollow">
+<title>Uyarı: Bu bağlantı engellendi</title>
+</head>
+<body>
+<h1>Bu bağlantı güvenlik incelemesinde</h1>
+<p><code>{{.ShortCode}}</code> kısa bağlantısının hedefi zararlı olarak işaretlendi ve yönlendirme durduruldu.</p>
+<p>Bağlantıyı size gönderen kişiye güvenmiyorsanız bu sayfayı kapatın.</p>
+</body>
+</html>
+`))
+
+// renderWarningPage responds with the quarantine warning page
+func renderWarningPage(c *gin.Context, shortCode string) {
+	var page bytes.Buffer
+	if err := warningPage.Execute(&page, gin.H{"ShortCode": shortCode}); err != nil {
+		logger.Error("Failed to render warning page", zap.Error(err))
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bu bağlantı güvenlik nedeniyle engellendi"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusForbidden, "text/html; charset=utf-8", page.Bytes())
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/service"
+	"go.uber.org/zap"
+)
+
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
+}
+
+// CreateWorkspace creates a workspace
+// @Summary Create workspace
+// @Description Create a workspace with its first owner (requires admin Bearer token). The owner then gets an API key through POST /api/v1/keys.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.CreateWorkspaceRequest true "Workspace ID, name and owner member ID"
+// @Success 201 {object} model.Workspace
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req model.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warn("Invalid workspace request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(&req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid workspace id":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Çalışma alanı ID sadece harf, rakam, '-' ve '_' içerebilir"})
+			return
+		case "name is required":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Çalışma alanı adı gereklidir"})
+			return
+		case "member_id is required":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Üye ID gereklidir"})
+			return
+		case "workspace already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu çalışma alanı zaten mevcut"})
+			return
+		}
+		logger.Error("Failed to create workspace", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Çalışma alanı oluşturulamadı"})
+		return
+	}
+
+	logger.Info("Workspace created", zap.String("workspace_id", workspace.ID))
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// ListWorkspaces lists workspaces
+// @Summary List workspaces
+// @Description List workspaces (requires Bearer token). Admins see every workspace, others their own.
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} model.ListWorkspacesResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/workspaces [get]
+func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
+	response, err := h.workspaceService.ListWorkspaces(currentPrincipal(c))
+	if err != nil {
+		logger.Error("Failed to list workspaces", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Çalışma alanları listelenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListMembers lists the members of a workspace
+// @Summary List workspace members
+// @Description List the members of a workspace and their roles (requires Bearer token of a member of the workspace)
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} model.ListWorkspaceMembersResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/workspaces/{id}/members [get]
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	response, err := h.workspaceService.ListMembers(currentPrincipal(c), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Üyeler listelenemedi")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddMember adds a member to a workspace
+// @Summary Add workspace member
+// @Description Add a member with a role (requires Bearer token with the admin role). Only owners can add admins and owners.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Workspace ID"
+// @Param request body model.AddWorkspaceMemberRequest true "Member ID and role"
+// @Success 201 {object} model.WorkspaceMember
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/workspaces/{id}/members [post]
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	var req model.AddWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warn("Invalid member request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+		return
+	}
+
+	workspaceID := c.Param("id")
+	member, err := h.workspaceService.AddMember(currentPrincipal(c), workspaceID, &req)
+	if err != nil {
+		h.respondError(c, err, "Üye eklenemedi")
+		return
+	}
+
+	logger.Info("Workspace member added", zap.String("workspace_id", workspaceID), zap.String("member_id", member.MemberID), zap.String("role", member.Role))
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMember changes the role of a workspace member
+// @Summary Update workspace member
+// @Description Change the role of a member (requires Bearer token with the admin role). Only owners can change admins and owners; the last owner can't be demoted.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Workspace ID"
+// @Param member path string true "Member ID"
+// @Param request body model.UpdateWorkspaceMemberRequest true "New role"
+// @Success 200 {object} model.WorkspaceMember
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/workspaces/{id}/members/{member} [patch]
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	var req model.UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warn("Invalid member request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek formatı"})
+		return
+	}
+
+	workspaceID := c.Param("id")
+	member, err := h.workspaceService.UpdateMember(currentPrincipal(c), workspaceID, c.Param("member"), &req)
+	if err != nil {
+		h.respondError(c, err, "Üye güncellenemedi")
+		return
+	}
+
+	logger.Info("Workspace member updated", zap.String("workspace_id", workspaceID), zap.String("member_id", member.MemberID), zap.String("role", member.Role))
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a member from a workspace
+// @Summary Remove workspace member
+// @Description Remove a member, their API keys stop working immediately (requires Bearer token with the admin role). Only owners can remove admins and owners; the last owner can't be removed.
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Workspace ID"
+// @Param member path string true "Member ID"
+// @Success 200 {object} model.SuccessResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/workspaces/{id}/members/{member} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	workspaceID, memberID := c.Param("id"), c.Param("member")
+	if err := h.workspaceService.RemoveMember(currentPrincipal(c), workspaceID, memberID); err != nil {
+		h.respondError(c, err, "Üye çıkarılamadı")
+		return
+	}
+
+	logger.Info("Workspace member removed", zap.String("workspace_id", workspaceID), zap.String("member_id", memberID))
+	c.JSON(http.StatusOK, gin.H{"message": "Üye çalışma alanından çıkarıldı"})
+}
+
+// respondError maps member management errors to responses
+func (h *WorkspaceHandler) respondError(c *gin.Context, err error, internalMessage string) {
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yetkiniz yok"})
+		return
+	}
+	switch err.Error() {
+	case "member_id is required":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Üye ID gereklidir"})
+	case "invalid role":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz rol, owner, admin, editor veya viewer olmalıdır"})
+	case "workspace not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Çalışma alanı bulunamadı"})
+	case "member not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Çalışma alanı üyesi bulunamadı"})
+	case "member already exists":
+		c.JSON(http.StatusConflict, gin.H{"error": "Bu üye zaten çalışma alanında"})
+	case "last owner":
+		c.JSON(http.StatusConflict, gin.H{"error": "Çalışma alanının son sahibi çıkarılamaz veya rolü düşürülemez"})
+	default:
+		logger.Error("Failed to manage workspace members", zap.Error(err), zap.String("workspace_id", c.Param("id")))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": internalMessage})
+	}
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+const (
+	ReportStatusOpen     = "open"
+	ReportStatusResolved = "resolved"
+)
+
+// AbuseReport is a complaint about a short URL's destination sent by an outsider
+type AbuseReport struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ShortCode  string     `gorm:"index;size:64;not null" json:"short_code"`
+	Reason     string     `gorm:"size:1000;not null" json:"reason"`
+	Contact    string     `gorm:"size:255" json:"contact,omitempty"`
+	IPHash     string     `gorm:"size:64" json:"ip_hash,omitempty"`
+	Status     string     `gorm:"size:16;not null;default:open;index" json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type CreateAbuseReportRequest struct {
+	Reason  string `json:"reason" binding:"required,max=1000" example:"Banka giriş sayfasını taklit ediyor"`
+	Contact string `json:"contact,omitempty" binding:"omitempty,max=255" example:"security@example.com"`
+}
+
+type CreateAbuseReportResponse struct {
+	ID        uint      `json:"id"`
+	ShortCode string    `json:"short_code"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListAbuseReportsRequest struct {
+	Status string `form:"status" example:"open"`
+	Code   string `form:"code"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+type ListAbuseReportsResponse struct {
+	Items      []AbuseReport `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type ModerateShortURLRequest struct {
+	Reason string `json:"reason,omitempty" example:"Phishing, rapor #42"`
+}
+
+// ModerationResponse is the state of a short URL after it was disabled or restored
+type ModerationResponse struct {
+	ShortCode        string     `json:"short_code"`
+	Disabled         bool       `json:"disabled"`
+	QuarantinedAt    *time.Time `json:"quarantined_at,omitempty"`
+	QuarantineReason string     `json:"quarantine_reason,omitempty"`
+	ResolvedReports  int64      `json:"resolved_reports"`
+}
@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// Credential scopes. Scopes limit what a credential may do; workspace routes
+// additionally check the member's role.
+const (
+	ScopeLinksWrite = "links:write"
+	ScopeLinksRead  = "links:read"
+	ScopeStatsRead  = "stats:read"
+	// ScopeAdmin is needed for workspace administration and, on admin
+	// credentials, for instance administration
+	ScopeAdmin = "admin"
+)
+
+// APIKey is a revocable credential of one workspace member. Only the SHA-256
+// hash of the key is stored; the key itself is shown once when it is created.
+type APIKey struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	WorkspaceID string     `gorm:"size:100;not null;index" json:"workspace_id"`
+	MemberID    string     `gorm:"size:100;index" json:"member_id,omitempty"`
+	Prefix      string     `gorm:"size:16;not null" json:"prefix"`
+	KeyHash     string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Admin       bool       `gorm:"not null;default:false" json:"admin"`
+	Scopes      []string   `gorm:"serializer:json;type:text" json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	WorkspaceID string
+	MemberID    string
+	// Role is the member's current role in the workspace
+	Role string
+	// KeyID is zero for the bootstrap admin token
+	KeyID uint
+	// Admin is an instance administrator with access to every workspace. It
+	// requires the admin scope on the credential.
+	Admin bool
+	// Scopes are the scopes of the credential
+	Scopes []string
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"growth-team-ci"`
+	// WorkspaceID and Admin can only be set by instance admins, others create keys in their own workspace
+	WorkspaceID string `json:"workspace_id,omitempty" binding:"omitempty,max=100" example:"growth-team"`
+	// MemberID defaults to the caller; workspace admins can issue keys to other members
+	MemberID string `json:"member_id,omitempty" binding:"omitempty,max=100" example:"bob@example.com"`
+	Admin    bool   `json:"admin,omitempty"`
+	// Scopes default to links:write, links:read and stats:read, plus admin for
+	// admin keys. They can't exceed the scopes of the caller.
+	Scopes    []string   `json:"scopes,omitempty" example:"links:read,stats:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	// Key is the plaintext key, it can't be retrieved again
+	Key string `json:"key" example:"sk_4f9c2a1b..."`
+}
+
+type ListAPIKeysResponse struct {
+	Items []APIKey `json:"items"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// Click is a single recorded visit of a short URL
+type Click struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ShortCode string    `gorm:"index:idx_clicks_code_time,priority:1;size:64;not null" json:"short_code"`
+	ClickedAt time.Time `gorm:"index:idx_clicks_code_time,priority:2;not null" json:"clicked_at"`
+	Referrer  string    `json:"referrer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IPHash    string    `gorm:"size:64" json:"ip_hash,omitempty"`
+
+	// Derived from the headers above when the click is recorded
+	ReferrerDomain string `gorm:"size:255" json:"referrer_domain,omitempty"`
+	Browser        string `gorm:"size:64" json:"browser,omitempty"`
+	OS             string `gorm:"size:64" json:"os,omitempty"`
+	DeviceType     string `gorm:"size:16" json:"device_type,omitempty"`
+	Country        string `gorm:"size:2" json:"country,omitempty"`
+	City           string `gorm:"size:128" json:"city,omitempty"`
+}
+
+// ClickInfo carries the request details of a visit before it is recorded
+type ClickInfo struct {
+	Referrer  string
+	UserAgent string
+	IP        string
+}
+
+// ClickStats is the click summary of a short URL
+type ClickStats struct {
+	TotalClicks         int64
+	UniqueVisitors      int64
+	UniqueVisitorsToday int64
+	LastClickAt         *time.Time
+}
+
+const (
+	DimensionReferrer = "referrer"
+	DimensionBrowser  = "browser"
+	DimensionOS       = "os"
+	DimensionDevice   = "device"
+	DimensionCountry  = "country"
+	DimensionCity     = "city"
+)
+
+type BreakdownItem struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+type BreakdownResponse struct {
+	ShortCode string          `json:"short_code"`
+	Dimension string          `json:"dimension"`
+	Items     []BreakdownItem `json:"items"`
+}
+
+// ClickPipelineMetrics is a snapshot of the asynchronous click ingestion counters
+type ClickPipelineMetrics struct {
+	Enqueued      uint64 `json:"enqueued"`
+	Dropped       uint64 `json:"dropped"`
+	Flushed       uint64 `json:"flushed"`
+	Failed        uint64 `json:"failed"`
+	QueueLength   int    `json:"queue_length"`
+	QueueCapacity int    `json:"queue_capacity"`
+}
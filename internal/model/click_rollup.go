@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+// ClickRollup is the pre-aggregated click count of a short URL for one time bucket
+type ClickRollup struct {
+	ShortCode   string    `gorm:"primaryKey;size:64" json:"short_code"`
+	Granularity string    `gorm:"primaryKey;size:8" json:"granularity"`
+	BucketStart time.Time `gorm:"primaryKey" json:"bucket_start"`
+	Clicks      int64     `gorm:"not null;default:0" json:"clicks"`
+}
+
+type TimeSeriesPoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Clicks      int64     `json:"clicks"`
+}
+
+type TimeSeriesResponse struct {
+	ShortCode string            `json:"short_code"`
+	Interval  string            `json:"interval"`
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Points    []TimeSeriesPoint `json:"points"`
+}
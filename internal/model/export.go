@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type ExportShortURLsRequest struct {
+	Format      string     `form:"format" example:"csv"`
+	Domain      string     `form:"domain" example:"example.com"`
+	Status      string     `form:"status" example:"active"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type ExportClicksRequest struct {
+	Format string     `form:"format" example:"ndjson"`
+	Code   string     `form:"code" example:"abc123"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// ShortURLExportRow uses the import field names so exports can be imported again
+type ShortURLExportRow struct {
+	Code        string     `json:"code"`
+	Destination string     `json:"destination"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ClickCount  int64      `json:"click_count"`
+}
+
+type ClickExportRow struct {
+	ID             uint      `json:"id"`
+	ShortCode      string    `json:"short_code"`
+	ClickedAt      time.Time `json:"clicked_at"`
+	Referrer       string    `json:"referrer"`
+	ReferrerDomain string    `json:"referrer_domain"`
+	UserAgent      string    `json:"user_agent"`
+	Browser        string    `json:"browser"`
+	OS             string    `json:"os"`
+	DeviceType     string    `json:"device_type"`
+	Country        string    `json:"country"`
+	City           string    `json:"city"`
+	IPHash         string    `json:"ip_hash"`
+}
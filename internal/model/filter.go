@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+const (
+	SortCreatedAt = "created_at"
+	SortClicks    = "clicks"
+	SortExpiresAt = "expires_at"
+
+	StatusActive  = "active"
+	StatusExpired = "expired"
+	// StatusQuarantined selects links flagged by the URL scanner, expired or not
+	StatusQuarantined = "quarantined"
+)
+
+// ShortURLFilter selects and orders short URLs for listing
+type ShortURLFilter struct {
+	// WorkspaceID limits the results to one workspace's links when set
+	WorkspaceID string
+	Domain      string
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string
+	Descending  bool
+	// After continues a keyset pagination from the given position
+	After *ShortURLCursor
+	Limit int
+}
+
+// ShortURLCursor is the sort key and ID of the last item of a page
+type ShortURLCursor struct {
+	Sort       string    `json:"s"`
+	Descending bool      `json:"d"`
+	Time       time.Time `json:"t,omitempty"`
+	Clicks     int64     `json:"c,omitempty"`
+	ID         uint      `json:"i"`
+}
+
+// NoExpirySortKey orders links without expiry after every expiring link
+var NoExpirySortKey = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// NewShortURLCursor returns the cursor positioned after the given short URL
+func NewShortURLCursor(shortURL *ShortURL, sort string, descending bool) *ShortURLCursor {
+	cursor := &ShortURLCursor{Sort: sort, Descending: descending, ID: shortURL.ID}
+	switch sort {
+	case SortClicks:
+		cursor.Clicks = shortURL.ClickCount
+	case SortExpiresAt:
+		cursor.Time = NoExpirySortKey
+		if shortURL.ExpiresAt != nil {
+			cursor.Time = *shortURL.ExpiresAt
+		}
+	default:
+		cursor.Time = shortURL.CreatedAt
+	}
+	return cursor
+}
+
+// ClickFilter selects raw clicks for export, in ID order
+type ClickFilter struct {
+	ShortCode string
+	From      *time.Time
+	To        *time.Time
+	// AfterID continues a keyset scan after the given click ID
+	AfterID uint
+	Limit   int
+}
+
+// AbuseReportFilter selects abuse reports, newest first
+type AbuseReportFilter struct {
+	Status    string
+	ShortCode string
+	// BeforeID continues a keyset scan below the given report ID
+	BeforeID uint
+	Limit    int
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ImportRecord is a single link read from an import file
+type ImportRecord struct {
+	Code        string     `json:"code"`
+	Destination string     `json:"destination"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+type ImportRowError struct {
+	Line   int    `json:"line"`
+	Code   string `json:"code,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type ImportReport struct {
+	TotalRows   int              `json:"total_rows"`
+	Imported    int              `json:"imported"`
+	Conflicts   int              `json:"conflicts"`
+	Invalid     int              `json:"invalid"`
+	Quarantined int              `json:"quarantined"`
+	Errors      []ImportRowError `json:"errors"`
+	// ErrorsTruncated is set when more rows failed than are listed in Errors
+	ErrorsTruncated bool `json:"errors_truncated,omitempty"`
+}
@@ -16,3 +16,9 @@ type HealthResponse struct {
 	Service string `json:"service" example:"url-shortener"`
 	Version string `json:"version" example:"1.0.0"`
 }
+
+// MetricsResponse represents internal service metrics
+type MetricsResponse struct {
+	ClickPipeline ClickPipelineMetrics `json:"click_pipeline"`
+	CodeGenerator CodeGeneratorMetrics `json:"code_generator"`
+}
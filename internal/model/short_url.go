@@ -7,30 +7,109 @@ import (
 )
 
 type ShortURL struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	ShortCode   string         `gorm:"uniqueIndex;size:10;not null" json:"short_code"`
-	OriginalURL string         `gorm:"not null" json:"original_url"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	ExpiresAt   *time.Time     `json:"expires_at,omitempty"`
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	ShortCode        string         `gorm:"uniqueIndex;size:64;not null" json:"short_code"`
+	OriginalURL      string         `gorm:"not null" json:"original_url"`
+	WorkspaceID      string         `gorm:"size:100;index" json:"workspace_id,omitempty"`
+	URLHash          string         `gorm:"size:64;index" json:"-"`
+	ClickCount       int64          `gorm:"not null;default:0;index" json:"click_count"`
+	CreatedAt        time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+	ExpiresAt        *time.Time     `json:"expires_at,omitempty"`
+	QuarantinedAt    *time.Time     `gorm:"index" json:"quarantined_at,omitempty"`
+	QuarantineReason string         `json:"quarantine_reason,omitempty"`
 }
 
 type CreateShortURLRequest struct {
-	URL       string     `json:"url" binding:"required,url"`
-	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	URL         string     `json:"url" binding:"required,url"`
+	Alias       string     `json:"alias,omitempty" example:"spring-sale"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Deduplicate *bool      `json:"deduplicate,omitempty"`
+}
+
+type BatchCreateShortURLRequest struct {
+	Items []CreateShortURLRequest `json:"items" binding:"required,min=1"`
+}
+
+const (
+	BatchStatusCreated = "created"
+	BatchStatusError   = "error"
+)
+
+type BatchItemResult struct {
+	Index  int                     `json:"index"`
+	Status string                  `json:"status" example:"created"`
+	Result *CreateShortURLResponse `json:"result,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+type BatchCreateShortURLResponse struct {
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Items   []BatchItemResult `json:"items"`
+}
+
+type UpdateShortURLRequest struct {
+	URL          *string    `json:"url,omitempty" binding:"omitempty,url"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RemoveExpiry bool       `json:"remove_expiry,omitempty"`
 }
 
 type CreateShortURLResponse struct {
-	ShortCode   string     `json:"short_code"`
-	ShortURL    string     `json:"short_url"`
-	OriginalURL string     `json:"original_url"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ShortCode    string     `json:"short_code"`
+	ShortURL     string     `json:"short_url"`
+	OriginalURL  string     `json:"original_url"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Deduplicated bool       `json:"deduplicated,omitempty"`
+	Quarantined  bool       `json:"quarantined,omitempty"`
 }
 
 type URLStatsResponse struct {
-	ShortCode   string     `json:"short_code"`
-	OriginalURL string     `json:"original_url"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ShortCode           string     `json:"short_code"`
+	OriginalURL         string     `json:"original_url"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ExpiresAt           *time.Time `json:"expires_at,omitempty"`
+	TotalClicks         int64      `json:"total_clicks"`
+	UniqueVisitors      int64      `json:"unique_visitors"`
+	UniqueVisitorsToday int64      `json:"unique_visitors_today"`
+	LastClickAt         *time.Time `json:"last_click_at,omitempty"`
+}
+
+type ListShortURLsRequest struct {
+	Cursor      string     `form:"cursor"`
+	Limit       int        `form:"limit"`
+	Sort        string     `form:"sort" example:"created_at"`
+	Order       string     `form:"order" example:"desc"`
+	Domain      string     `form:"domain" example:"example.com"`
+	Status      string     `form:"status" example:"active"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type ShortURLListItem struct {
+	ShortCode        string     `json:"short_code"`
+	ShortURL         string     `json:"short_url"`
+	OriginalURL      string     `json:"original_url"`
+	ClickCount       int64      `json:"click_count"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	QuarantinedAt    *time.Time `json:"quarantined_at,omitempty"`
+	QuarantineReason string     `json:"quarantine_reason,omitempty"`
+}
+
+type ListShortURLsResponse struct {
+	Items      []ShortURLListItem `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// CodeGeneratorMetrics is a snapshot of short code generation counters
+type CodeGeneratorMetrics struct {
+	Strategy        string  `json:"strategy"`
+	Length          int     `json:"length"`
+	MaxLength       int     `json:"max_length,omitempty"`
+	Generated       uint64  `json:"generated"`
+	Collisions      uint64  `json:"collisions"`
+	CollisionRate   float64 `json:"collision_rate"`
+	LengthIncreases uint64  `json:"length_increases"`
 }
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// Workspace roles, from most to least privileged. Each role has the permissions
+// of the roles below it.
+const (
+	// RoleOwner additionally manages owners and admins
+	RoleOwner = "owner"
+	// RoleAdmin additionally manages editors, viewers and their API keys
+	RoleAdmin = "admin"
+	// RoleEditor additionally creates, updates and deletes links
+	RoleEditor = "editor"
+	// RoleViewer reads links and their analytics
+	RoleViewer = "viewer"
+)
+
+// Workspace isolates the links and API keys of one team from the others
+type Workspace struct {
+	ID        string    `gorm:"primaryKey;size:100" json:"id"`
+	Name      string    `gorm:"size:200;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkspaceMember grants a member a role in a workspace. Members authenticate
+// with API keys issued to them.
+type WorkspaceMember struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WorkspaceID string    `gorm:"size:100;not null;uniqueIndex:idx_workspace_member" json:"workspace_id"`
+	MemberID    string    `gorm:"size:100;not null;uniqueIndex:idx_workspace_member" json:"member_id"`
+	Role        string    `gorm:"size:20;not null" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CreateWorkspaceRequest struct {
+	ID   string `json:"id" binding:"required,max=100" example:"growth-team"`
+	Name string `json:"name" binding:"required,max=200" example:"Growth"`
+	// Owner is the member ID of the first owner
+	Owner string `json:"owner" binding:"required,max=100" example:"alice@example.com"`
+}
+
+type ListWorkspacesResponse struct {
+	Items []Workspace `json:"items"`
+}
+
+type AddWorkspaceMemberRequest struct {
+	MemberID string `json:"member_id" binding:"required,max=100" example:"bob@example.com"`
+	Role     string `json:"role" binding:"required" example:"editor" enums:"owner,admin,editor,viewer"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" binding:"required" example:"viewer" enums:"owner,admin,editor,viewer"`
+}
+
+type ListWorkspaceMembersResponse struct {
+	Items []WorkspaceMember `json:"items"`
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/shortener/internal/model"
+	"gorm.io/gorm"
+)
+
+type AbuseReportRepository interface {
+	Create(report *model.AbuseReport) error
+	List(filter *model.AbuseReportFilter) ([]model.AbuseReport, error)
+	ResolveByCode(shortCode string) (int64, error)
+}
+
+type abuseReportRepository struct {
+	db *gorm.DB
+}
+
+func NewAbuseReportRepository(db *gorm.DB) AbuseReportRepository {
+	return &abuseReportRepository{db: db}
+}
+
+func (r *abuseReportRepository) Create(report *model.AbuseReport) error {
+	return r.db.Create(report).Error
+}
+
+// List returns reports matching the filter, newest first, using keyset pagination on ID
+func (r *abuseReportRepository) List(filter *model.AbuseReportFilter) ([]model.AbuseReport, error) {
+	query := r.db.Model(&model.AbuseReport{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ShortCode != "" {
+		query = query.Where("short_code = ?", filter.ShortCode)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var reports []model.AbuseReport
+	if err := query.Order("id DESC").Limit(filter.Limit).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// ResolveByCode closes the open reports of a short URL and returns how many were closed
+func (r *abuseReportRepository) ResolveByCode(shortCode string) (int64, error) {
+	result := r.db.Model(&model.AbuseReport{}).
+		Where("short_code = ? AND status = ?", shortCode, model.ReportStatusOpen).
+		Updates(map[string]interface{}{
+			"status":      model.ReportStatusResolved,
+			"resolved_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/shortener/internal/model"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(key *model.APIKey) error
+	FindByHash(keyHash string) (*model.APIKey, error)
+	FindByID(id uint) (*model.APIKey, error)
+	List(workspaceID, memberID string) ([]model.APIKey, error)
+	Revoke(id uint) error
+	TouchLastUsed(id uint, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) FindByHash(keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByID(id uint) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List returns the keys of a workspace, optionally of one member only. Empty
+// values match every workspace or member.
+func (r *apiKeyRepository) List(workspaceID, memberID string) ([]model.APIKey, error) {
+	query := r.db.Model(&model.APIKey{})
+	if workspaceID != "" {
+		query = query.Where("workspace_id = ?", workspaceID)
+	}
+	if memberID != "" {
+		query = query.Where("member_id = ?", memberID)
+	}
+
+	var keys []model.APIKey
+	if err := query.Order("id ASC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marks a key as revoked, keeping the first revocation time
+func (r *apiKeyRepository) Revoke(id uint) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shortener/internal/model"
+	"gorm.io/gorm"
+)
+
+type ClickRepository interface {
+	CreateBatch(clicks []*model.Click) error
+	GetStats(shortCode string) (*model.ClickStats, error)
+	CountByDimension(shortCode, dimension string, limit int) ([]model.BreakdownItem, error)
+	List(filter *model.ClickFilter) ([]model.Click, error)
+}
+
+// dimensionColumns whitelists the click columns that can be grouped on.
+// City names are ambiguous on their own, so they are qualified by country.
+var dimensionColumns = map[string]string{
+	model.DimensionReferrer: "referrer_domain",
+	model.DimensionBrowser:  "browser",
+	model.DimensionOS:       "os",
+	model.DimensionDevice:   "device_type",
+	model.DimensionCountry:  "country",
+	model.DimensionCity:     "CASE WHEN city = '' THEN '' ELSE city || ', ' || country END",
+}
+
+type clickRepository struct {
+	db *gorm.DB
+}
+
+func NewClickRepository(db *gorm.DB) ClickRepository {
+	return &clickRepository{db: db}
+}
+
+func (r *clickRepository) CreateBatch(clicks []*model.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int64)
+	for _, click := range clicks {
+		counts[click.ShortCode]++
+	}
+	// Update counters in a stable order so concurrent batches can't deadlock
+	codes := make([]string, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(clicks, len(clicks)).Error; err != nil {
+			return err
+		}
+		for _, code := range codes {
+			err := tx.Model(&model.ShortURL{}).
+				Where("short_code = ?", code).
+				UpdateColumn("click_count", gorm.Expr("click_count + ?", counts[code])).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *clickRepository) GetStats(shortCode string) (*model.ClickStats, error) {
+	var stats model.ClickStats
+	err := r.db.Model(&model.Click{}).
+		Select("COUNT(*) AS total_clicks, MAX(clicked_at) AS last_click_at").
+		Where("short_code = ?", shortCode).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (r *clickRepository) CountByDimension(shortCode, dimension string, limit int) ([]model.BreakdownItem, error) {
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unsupported dimension: %s", dimension)
+	}
+
+	var items []model.BreakdownItem
+	err := r.db.Model(&model.Click{}).
+		Select(column+" AS value, COUNT(*) AS clicks").
+		Where("short_code = ?", shortCode).
+		Group(column).
+		Order("clicks DESC").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *clickRepository) List(filter *model.ClickFilter) ([]model.Click, error) {
+	query := r.db.Model(&model.Click{}).Where("id > ?", filter.AfterID)
+	if filter.ShortCode != "" {
+		query = query.Where("short_code = ?", filter.ShortCode)
+	}
+	if filter.From != nil {
+		query = query.Where("clicked_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("clicked_at < ?", *filter.To)
+	}
+
+	var clicks []model.Click
+	if err := query.Order("id ASC").Limit(filter.Limit).Find(&clicks).Error; err != nil {
+		return nil, err
+	}
+	return clicks, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/shortener/internal/model"
+	"gorm.io/gorm"
+)
+
+type ClickRollupRepository interface {
+	LatestBucket(granularity string) (*time.Time, error)
+	RollupHourly(from, to time.Time) error
+	RollupDaily(from, to time.Time) error
+	FindRange(shortCode, granularity string, from, to time.Time) ([]model.ClickRollup, error)
+}
+
+type clickRollupRepository struct {
+	db *gorm.DB
+}
+
+func NewClickRollupRepository(db *gorm.DB) ClickRollupRepository {
+	return &clickRollupRepository{db: db}
+}
+
+func (r *clickRollupRepository) LatestBucket(granularity string) (*time.Time, error) {
+	var result struct {
+		Latest *time.Time
+	}
+	err := r.db.Model(&model.ClickRollup{}).
+		Select("MAX(bucket_start) AS latest").
+		Where("granularity = ?", granularity).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result.Latest, nil
+}
+
+// RollupHourly recomputes the hourly buckets of all clicks in [from, to).
+// Counts are overwritten, so running it again for the same range is safe.
+func (r *clickRollupRepository) RollupHourly(from, to time.Time) error {
+	return r.db.Exec(`
+		INSERT INTO click_rollups (short_code, granularity, bucket_start, clicks)
+		SELECT short_code, ?, date_trunc('hour', clicked_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC', COUNT(*)
+		FROM clicks
+		WHERE clicked_at >= ? AND clicked_at < ?
+		GROUP BY short_code, date_trunc('hour', clicked_at AT TIME ZONE 'UTC')
+		ON CONFLICT (short_code, granularity, bucket_start) DO UPDATE SET clicks = EXCLUDED.clicks`,
+		model.GranularityHour, from, to,
+	).Error
+}
+
+// RollupDaily recomputes the daily buckets in [from, to) from the hourly buckets
+func (r *clickRollupRepository) RollupDaily(from, to time.Time) error {
+	return r.db.Exec(`
+		INSERT INTO click_rollups (short_code, granularity, bucket_start, clicks)
+		SELECT short_code, ?, date_trunc('day', bucket_start AT TIME ZONE 'UTC') AT TIME ZONE 'UTC', SUM(clicks)
+		FROM click_rollups
+		WHERE granularity = ? AND bucket_start >= ? AND bucket_start < ?
+		GROUP BY short_code, date_trunc('day', bucket_start AT TIME ZONE 'UTC')
+		ON CONFLICT (short_code, granularity, bucket_start) DO UPDATE SET clicks = EXCLUDED.clicks`,
+		model.GranularityDay, model.GranularityHour, from, to,
+	).Error
+}
+
+func (r *clickRollupRepository) FindRange(shortCode, granularity string, from, to time.Time) ([]model.ClickRollup, error) {
+	var rollups []model.ClickRollup
+	err := r.db.
+		Where("short_code = ? AND granularity = ? AND bucket_start >= ? AND bucket_start < ?", shortCode, granularity, from, to).
+		Order("bucket_start ASC").
+		Find(&rollups).Error
+	if err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
@@ -10,6 +10,9 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// CodeSequenceName is the Postgres sequence behind sequence based short codes
+const CodeSequenceName = "short_code_seq"
+
 func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
@@ -30,15 +33,42 @@ func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logLevel),
+		// Report unique violations as gorm.ErrDuplicatedKey instead of driver errors
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Links and keys belonged to a plain owner before workspaces existed, keep
+	// those values as workspace IDs
+	for _, table := range []interface{}{&model.ShortURL{}, &model.APIKey{}} {
+		if db.Migrator().HasColumn(table, "owner_id") {
+			if err := db.Migrator().RenameColumn(table, "owner_id", "workspace_id"); err != nil {
+				return nil, fmt.Errorf("failed to rename owner column: %w", err)
+			}
+		}
+	}
+
+	// click_count was added after clicks were recorded, fill it once when the
+	// column is created so sorting by clicks ranks existing links correctly
+	backfillClickCounts := db.Migrator().HasTable(&model.ShortURL{}) &&
+		!db.Migrator().HasColumn(&model.ShortURL{}, "click_count")
+
 	// Auto-migrate the schema
-	if err := db.AutoMigrate(&model.ShortURL{}); err != nil {
+	if err := db.AutoMigrate(&model.ShortURL{}, &model.Click{}, &model.ClickRollup{}, &model.AbuseReport{}, &model.APIKey{}, &model.Workspace{}, &model.WorkspaceMember{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
+	if backfillClickCounts {
+		err := db.Exec(`UPDATE short_urls SET click_count =
+			(SELECT count(*) FROM clicks WHERE clicks.short_code = short_urls.short_code)`).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to backfill click counts: %w", err)
+		}
+	}
+	if err := db.Exec("CREATE SEQUENCE IF NOT EXISTS " + CodeSequenceName).Error; err != nil {
+		return nil, fmt.Errorf("failed to create short code sequence: %w", err)
+	}
 
 	return db, nil
 }
@@ -1,16 +1,29 @@
 package repository
 
 import (
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/shortener/internal/model"
 	"gorm.io/gorm"
 )
 
+// ErrDuplicateShortCode is returned when an insert hits the unique short code index
+var ErrDuplicateShortCode = errors.New("short code already exists")
+
 type ShortURLRepository interface {
 	Create(shortURL *model.ShortURL) error
+	CreateBatch(shortURLs []*model.ShortURL) error
+	FindExistingCodes(shortCodes []string) ([]string, error)
+	NextCodeSequence(n int) ([]int64, error)
 	FindByCode(shortCode string) (*model.ShortURL, error)
+	FindActiveByHash(workspaceID, urlHash string, expiresAt *time.Time) (*model.ShortURL, error)
 	FindByID(id uint) (*model.ShortURL, error)
+	List(filter *model.ShortURLFilter) ([]model.ShortURL, error)
+	Update(shortURL *model.ShortURL) error
+	Quarantine(shortCode, reason string) error
+	ReleaseQuarantine(shortCode string) error
 	Delete(shortCode string) error
 }
 
@@ -23,7 +36,54 @@ func NewShortURLRepository(db *gorm.DB) ShortURLRepository {
 }
 
 func (r *shortURLRepository) Create(shortURL *model.ShortURL) error {
-	return r.db.Create(shortURL).Error
+	return translateDuplicate(r.db.Create(shortURL).Error)
+}
+
+// CreateBatch inserts all short URLs in one transaction, either all or none are stored
+func (r *shortURLRepository) CreateBatch(shortURLs []*model.ShortURL) error {
+	if len(shortURLs) == 0 {
+		return nil
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(shortURLs, 100).Error
+	})
+	return translateDuplicate(err)
+}
+
+// translateDuplicate maps unique constraint violations to ErrDuplicateShortCode.
+// short_code is the only unique column besides the primary key.
+func translateDuplicate(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateShortCode
+	}
+	return err
+}
+
+// FindExistingCodes returns which of the given codes are already taken,
+// including soft deleted rows that still hold the unique index
+func (r *shortURLRepository) FindExistingCodes(shortCodes []string) ([]string, error) {
+	if len(shortCodes) == 0 {
+		return nil, nil
+	}
+	var existing []string
+	err := r.db.Unscoped().Model(&model.ShortURL{}).
+		Where("short_code IN ?", shortCodes).
+		Pluck("short_code", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// NextCodeSequence reserves n values of the short code sequence in one query
+func (r *shortURLRepository) NextCodeSequence(n int) ([]int64, error) {
+	var values []int64
+	err := r.db.Raw("SELECT nextval('"+CodeSequenceName+"') FROM generate_series(1, ?)", n).
+		Scan(&values).Error
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
 }
 
 func (r *shortURLRepository) FindByCode(shortCode string) (*model.ShortURL, error) {
@@ -35,6 +95,23 @@ func (r *shortURLRepository) FindByCode(shortCode string) (*model.ShortURL, erro
 	return &shortURL, nil
 }
 
+// FindActiveByHash returns the workspace's oldest unexpired short URL with the given
+// destination hash and exactly the given expiry
+func (r *shortURLRepository) FindActiveByHash(workspaceID, urlHash string, expiresAt *time.Time) (*model.ShortURL, error) {
+	query := r.db.Where("workspace_id = ? AND url_hash = ?", workspaceID, urlHash)
+	if expiresAt == nil {
+		query = query.Where("expires_at IS NULL")
+	} else {
+		query = query.Where("expires_at = ? AND expires_at > ?", *expiresAt, time.Now())
+	}
+
+	var shortURL model.ShortURL
+	if err := query.Order("id ASC").First(&shortURL).Error; err != nil {
+		return nil, err
+	}
+	return &shortURL, nil
+}
+
 func (r *shortURLRepository) FindByID(id uint) (*model.ShortURL, error) {
 	var shortURL model.ShortURL
 	err := r.db.First(&shortURL, id).Error
@@ -44,6 +121,117 @@ func (r *shortURLRepository) FindByID(id uint) (*model.ShortURL, error) {
 	return &shortURL, nil
 }
 
+// List returns short URLs matching the filter using keyset pagination on the
+// sort column and ID, so deep pages cost the same as the first one
+func (r *shortURLRepository) List(filter *model.ShortURLFilter) ([]model.ShortURL, error) {
+	query := r.applyFilter(r.db.Model(&model.ShortURL{}), filter)
+
+	sortExpr := sortExpression(filter.Sort)
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		var value interface{} = filter.After.Time
+		if filter.Sort == model.SortClicks {
+			value = filter.After.Clicks
+		}
+		query = query.Where("("+sortExpr+", id) "+comparison+" (?, ?)", value, filter.After.ID)
+	}
+
+	var shortURLs []model.ShortURL
+	err := query.
+		Order(sortExpr + " " + direction).
+		Order("id " + direction).
+		Limit(filter.Limit).
+		Find(&shortURLs).Error
+	if err != nil {
+		return nil, err
+	}
+	return shortURLs, nil
+}
+
+func (r *shortURLRepository) applyFilter(query *gorm.DB, filter *model.ShortURLFilter) *gorm.DB {
+	if filter.WorkspaceID != "" {
+		query = query.Where("workspace_id = ?", filter.WorkspaceID)
+	}
+	if filter.Domain != "" {
+		// Match against the host part of the destination only
+		query = query.Where(
+			"substring(original_url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#]+)') ILIKE ?",
+			"%"+escapeLike(filter.Domain)+"%",
+		)
+	}
+
+	now := time.Now()
+	switch filter.Status {
+	case model.StatusActive:
+		query = query.Where("(expires_at IS NULL OR expires_at > ?)", now)
+	case model.StatusExpired:
+		query = query.Where("expires_at <= ?", now)
+	case model.StatusQuarantined:
+		query = query.Where("quarantined_at IS NOT NULL")
+	}
+
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+
+	return query
+}
+
+func sortExpression(sort string) string {
+	switch sort {
+	case model.SortClicks:
+		return "click_count"
+	case model.SortExpiresAt:
+		return "COALESCE(expires_at, '" + model.NoExpirySortKey.Format(time.RFC3339) + "')"
+	default:
+		return "created_at"
+	}
+}
+
+// escapeLike escapes LIKE wildcards in user input
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// Update writes the fields a PATCH can change. Click counts and quarantine state
+// are left alone, they may have changed since the row was loaded.
+func (r *shortURLRepository) Update(shortURL *model.ShortURL) error {
+	return r.db.Model(&model.ShortURL{}).
+		Where("id = ?", shortURL.ID).
+		Updates(map[string]interface{}{
+			"original_url": shortURL.OriginalURL,
+			"url_hash":     shortURL.URLHash,
+			"expires_at":   shortURL.ExpiresAt,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// Quarantine marks a short URL as flagged, keeping the first quarantine time
+func (r *shortURLRepository) Quarantine(shortCode, reason string) error {
+	return r.db.Model(&model.ShortURL{}).
+		Where("short_code = ? AND quarantined_at IS NULL", shortCode).
+		Updates(map[string]interface{}{
+			"quarantined_at":    time.Now(),
+			"quarantine_reason": reason,
+		}).Error
+}
+
+func (r *shortURLRepository) ReleaseQuarantine(shortCode string) error {
+	return r.db.Model(&model.ShortURL{}).
+		Where("short_code = ?", shortCode).
+		Updates(map[string]interface{}{
+			"quarantined_at":    nil,
+			"quarantine_reason": "",
+		}).Error
+}
+
 func (r *shortURLRepository) Delete(shortCode string) error {
 	return r.db.Where("short_code = ?", shortCode).Delete(&model.ShortURL{}).Error
 }
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"github.com/shortener/internal/model"
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepository interface {
+	CreateWithOwner(workspace *model.Workspace, owner *model.WorkspaceMember) error
+	FindByID(id string) (*model.Workspace, error)
+	List() ([]model.Workspace, error)
+	AddMember(member *model.WorkspaceMember) error
+	FindMember(workspaceID, memberID string) (*model.WorkspaceMember, error)
+	ListMembers(workspaceID string) ([]model.WorkspaceMember, error)
+	CountMembersWithRole(workspaceID, role string) (int64, error)
+	UpdateMemberRole(workspaceID, memberID, role string) error
+	RemoveMember(workspaceID, memberID string) error
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+// CreateWithOwner creates the workspace and its first owner in one transaction
+func (r *workspaceRepository) CreateWithOwner(workspace *model.Workspace, owner *model.WorkspaceMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		owner.WorkspaceID = workspace.ID
+		return tx.Create(owner).Error
+	})
+}
+
+func (r *workspaceRepository) FindByID(id string) (*model.Workspace, error) {
+	var workspace model.Workspace
+	if err := r.db.Where("id = ?", id).First(&workspace).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (r *workspaceRepository) List() ([]model.Workspace, error) {
+	var workspaces []model.Workspace
+	if err := r.db.Order("id ASC").Find(&workspaces).Error; err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) AddMember(member *model.WorkspaceMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *workspaceRepository) FindMember(workspaceID, memberID string) (*model.WorkspaceMember, error) {
+	var member model.WorkspaceMember
+	if err := r.db.Where("workspace_id = ? AND member_id = ?", workspaceID, memberID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *workspaceRepository) ListMembers(workspaceID string) ([]model.WorkspaceMember, error) {
+	var members []model.WorkspaceMember
+	if err := r.db.Where("workspace_id = ?", workspaceID).Order("id ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) CountMembersWithRole(workspaceID, role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, role).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *workspaceRepository) UpdateMemberRole(workspaceID, memberID, role string) error {
+	return r.db.Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND member_id = ?", workspaceID, memberID).
+		Update("role", role).Error
+}
+
+func (r *workspaceRepository) RemoveMember(workspaceID, memberID string) error {
+	return r.db.Where("workspace_id = ? AND member_id = ?", workspaceID, memberID).
+		Delete(&model.WorkspaceMember{}).Error
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shortener/internal/cache"
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrReportRateLimited is returned when a client sent too many abuse reports
+var ErrReportRateLimited = errors.New("too many reports")
+
+const (
+	defaultReportListLimit = 50
+	maxReportListLimit     = 200
+	// disabledReasonPrefix marks quarantines set by a moderator rather than a scanner
+	disabledReasonPrefix = "disabled by moderator"
+)
+
+// AbuseService records abuse reports from the public and lets moderators disable
+// and restore links. Disabled links use the same quarantine as scanner findings.
+type AbuseService interface {
+	ReportShortURL(shortCode string, req *model.CreateAbuseReportRequest, ip string) (*model.CreateAbuseReportResponse, error)
+	ListReports(req *model.ListAbuseReportsRequest) (*model.ListAbuseReportsResponse, error)
+	DisableShortURL(shortCode, reason string) (*model.ModerationResponse, error)
+	RestoreShortURL(shortCode string) (*model.ModerationResponse, error)
+}
+
+type abuseService struct {
+	reportRepo repository.AbuseReportRepository
+	urlRepo    repository.ShortURLRepository
+	cache      cache.CacheInterface
+	limiter    cache.RateLimitCache
+	config     *config.Config
+}
+
+func NewAbuseService(reportRepo repository.AbuseReportRepository, urlRepo repository.ShortURLRepository, cache cache.CacheInterface, limiter cache.RateLimitCache, cfg *config.Config) AbuseService {
+	return &abuseService{
+		reportRepo: reportRepo,
+		urlRepo:    urlRepo,
+		cache:      cache,
+		limiter:    limiter,
+		config:     cfg,
+	}
+}
+
+func (s *abuseService) ReportShortURL(shortCode string, req *model.CreateAbuseReportRequest, ip string) (*model.CreateAbuseReportResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	ipHash := hashIP(s.config.App.IPHashSalt, ip)
+	if err := s.checkRateLimit(ipHash); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findShortURL(shortCode); err != nil {
+		return nil, err
+	}
+
+	report := &model.AbuseReport{
+		ShortCode: shortCode,
+		Reason:    reason,
+		Contact:   strings.TrimSpace(req.Contact),
+		IPHash:    ipHash,
+		Status:    model.ReportStatusOpen,
+	}
+	if err := s.reportRepo.Create(report); err != nil {
+		return nil, fmt.Errorf("failed to create abuse report: %w", err)
+	}
+
+	return &model.CreateAbuseReportResponse{
+		ID:        report.ID,
+		ShortCode: report.ShortCode,
+		Status:    report.Status,
+		CreatedAt: report.CreatedAt,
+	}, nil
+}
+
+// checkRateLimit counts reports per client in a fixed window. Redis being
+// unavailable lets reports through, losing a report is worse than a burst.
+func (s *abuseService) checkRateLimit(ipHash string) error {
+	limit := s.config.Abuse.ReportRateLimit
+	if limit <= 0 || ipHash == "" {
+		return nil
+	}
+
+	window := time.Duration(s.config.Abuse.ReportRateWindowSec) * time.Second
+	count, err := s.limiter.Incr(fmt.Sprintf("report_rate:%s", ipHash), window)
+	if err != nil {
+		logger.Warn("Failed to check report rate limit", zap.Error(err))
+		return nil
+	}
+	if count > int64(limit) {
+		return ErrReportRateLimited
+	}
+	return nil
+}
+
+func (s *abuseService) ListReports(req *model.ListAbuseReportsRequest) (*model.ListAbuseReportsResponse, error) {
+	filter := &model.AbuseReportFilter{
+		Status:    req.Status,
+		ShortCode: req.Code,
+		Limit:     req.Limit,
+	}
+
+	switch filter.Status {
+	case "", model.ReportStatusOpen, model.ReportStatusResolved:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultReportListLimit
+	} else if filter.Limit > maxReportListLimit {
+		filter.Limit = maxReportListLimit
+	}
+
+	if req.Cursor != "" {
+		id, err := strconv.ParseUint(req.Cursor, 10, 64)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		filter.BeforeID = uint(id)
+	}
+
+	// Fetch one extra row to know whether another page exists
+	limit := filter.Limit
+	filter.Limit++
+	reports, err := s.reportRepo.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list abuse reports: %w", err)
+	}
+
+	response := &model.ListAbuseReportsResponse{Items: reports}
+	if len(reports) > limit {
+		response.Items = reports[:limit]
+		response.NextCursor = strconv.FormatUint(uint64(reports[limit-1].ID), 10)
+	}
+	if response.Items == nil {
+		response.Items = []model.AbuseReport{}
+	}
+	return response, nil
+}
+
+// DisableShortURL quarantines the link so redirects show the warning page and
+// closes its open reports
+func (s *abuseService) DisableShortURL(shortCode, reason string) (*model.ModerationResponse, error) {
+	if _, err := s.findShortURL(shortCode); err != nil {
+		return nil, err
+	}
+
+	quarantineReason := disabledReasonPrefix
+	if reason = strings.TrimSpace(reason); reason != "" {
+		quarantineReason += ": " + reason
+	}
+	if err := s.urlRepo.Quarantine(shortCode, quarantineReason); err != nil {
+		return nil, fmt.Errorf("failed to disable short URL: %w", err)
+	}
+
+	// Evict the cached destination so the next redirect sees the quarantine
+	if err := s.cache.Delete(fmt.Sprintf("short_url:%s", shortCode)); err != nil {
+		return nil, fmt.Errorf("failed to evict cached URL: %w", err)
+	}
+
+	return s.moderationResult(shortCode)
+}
+
+// RestoreShortURL lifts the quarantine of a link, whether a moderator or the
+// scanner set it, and closes its open reports
+func (s *abuseService) RestoreShortURL(shortCode string) (*model.ModerationResponse, error) {
+	if _, err := s.findShortURL(shortCode); err != nil {
+		return nil, err
+	}
+
+	if err := s.urlRepo.ReleaseQuarantine(shortCode); err != nil {
+		return nil, fmt.Errorf("failed to restore short URL: %w", err)
+	}
+
+	return s.moderationResult(shortCode)
+}
+
+func (s *abuseService) moderationResult(shortCode string) (*model.ModerationResponse, error) {
+	resolved, err := s.reportRepo.ResolveByCode(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve abuse reports: %w", err)
+	}
+
+	shortURL, err := s.findShortURL(shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ModerationResponse{
+		ShortCode:        shortURL.ShortCode,
+		Disabled:         shortURL.QuarantinedAt != nil,
+		QuarantinedAt:    shortURL.QuarantinedAt,
+		QuarantineReason: shortURL.QuarantineReason,
+		ResolvedReports:  resolved,
+	}, nil
+}
+
+func (s *abuseService) findShortURL(shortCode string) (*model.ShortURL, error) {
+	shortURL, err := s.urlRepo.FindByCode(shortCode)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("short URL not found")
+		}
+		return nil, fmt.Errorf("failed to find short URL: %w", err)
+	}
+	return shortURL, nil
+}
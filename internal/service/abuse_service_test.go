@@ -0,0 +1,183 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockAbuseReportRepository is a mock implementation of AbuseReportRepository
+type MockAbuseReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockAbuseReportRepository) Create(report *model.AbuseReport) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func (m *MockAbuseReportRepository) List(filter *model.AbuseReportFilter) ([]model.AbuseReport, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]model.AbuseReport), args.Error(1)
+}
+
+func (m *MockAbuseReportRepository) ResolveByCode(shortCode string) (int64, error) {
+	args := m.Called(shortCode)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// counterLimiter counts increments in memory, ignoring the window
+type counterLimiter map[string]int64
+
+func (l counterLimiter) Incr(key string, window time.Duration) (int64, error) {
+	l[key]++
+	return l[key], nil
+}
+
+func newTestAbuseConfig() *config.Config {
+	return &config.Config{
+		App:   config.AppConfig{IPHashSalt: "salt"},
+		Abuse: config.AbuseConfig{ReportRateLimit: 2, ReportRateWindowSec: 3600},
+	}
+}
+
+func TestReportShortURL(t *testing.T) {
+	// Setup
+	mockReports := new(MockAbuseReportRepository)
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	service := NewAbuseService(mockReports, mockRepo, mockCache, counterLimiter{}, newTestAbuseConfig())
+
+	// Mock expectations
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123"}, nil)
+	mockReports.On("Create", mock.MatchedBy(func(report *model.AbuseReport) bool {
+		return report.ShortCode == "abc123" &&
+			report.Reason == "phishing" &&
+			report.Status == model.ReportStatusOpen &&
+			report.IPHash == hashIP("salt", "203.0.113.7")
+	})).Return(nil)
+
+	// Execute
+	response, err := service.ReportShortURL("abc123", &model.CreateAbuseReportRequest{Reason: " phishing "}, "203.0.113.7")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReportStatusOpen, response.Status)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockReports.AssertExpectations(t)
+}
+
+func TestReportShortURL_RateLimited(t *testing.T) {
+	// Setup
+	mockReports := new(MockAbuseReportRepository)
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	service := NewAbuseService(mockReports, mockRepo, mockCache, counterLimiter{}, newTestAbuseConfig())
+	req := &model.CreateAbuseReportRequest{Reason: "spam"}
+
+	// Mock expectations
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123"}, nil)
+	mockReports.On("Create", mock.Anything).Return(nil)
+
+	// Execute
+	_, first := service.ReportShortURL("abc123", req, "203.0.113.7")
+	_, second := service.ReportShortURL("abc123", req, "203.0.113.7")
+	_, third := service.ReportShortURL("abc123", req, "203.0.113.7")
+	_, otherClient := service.ReportShortURL("abc123", req, "198.51.100.1")
+
+	// Assert
+	assert.NoError(t, first)
+	assert.NoError(t, second)
+	assert.ErrorIs(t, third, ErrReportRateLimited)
+	assert.NoError(t, otherClient)
+
+	// Verify mock calls
+	mockReports.AssertNumberOfCalls(t, "Create", 3)
+}
+
+func TestReportShortURL_NotFound(t *testing.T) {
+	// Setup
+	mockReports := new(MockAbuseReportRepository)
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	service := NewAbuseService(mockReports, mockRepo, mockCache, counterLimiter{}, newTestAbuseConfig())
+
+	// Mock expectations
+	mockRepo.On("FindByCode", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Execute
+	_, err := service.ReportShortURL("missing", &model.CreateAbuseReportRequest{Reason: "spam"}, "203.0.113.7")
+
+	// Assert
+	assert.EqualError(t, err, "short URL not found")
+	mockReports.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestListReports_Pagination(t *testing.T) {
+	// Setup
+	mockReports := new(MockAbuseReportRepository)
+	service := NewAbuseService(mockReports, new(MockShortURLRepository), new(MockRedisClient), counterLimiter{}, newTestAbuseConfig())
+
+	// Mock expectations
+	mockReports.On("List", &model.AbuseReportFilter{Status: model.ReportStatusOpen, BeforeID: 10, Limit: 3}).
+		Return([]model.AbuseReport{{ID: 9}, {ID: 8}, {ID: 7}}, nil)
+
+	// Execute
+	response, err := service.ListReports(&model.ListAbuseReportsRequest{Status: model.ReportStatusOpen, Cursor: "10", Limit: 2})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, response.Items, 2)
+	assert.Equal(t, "8", response.NextCursor)
+
+	_, err = service.ListReports(&model.ListAbuseReportsRequest{Status: "closed"})
+	assert.EqualError(t, err, "invalid status")
+}
+
+func TestDisableAndRestoreShortURL(t *testing.T) {
+	// Setup
+	mockReports := new(MockAbuseReportRepository)
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	service := NewAbuseService(mockReports, mockRepo, mockCache, counterLimiter{}, newTestAbuseConfig())
+	quarantinedAt := time.Now()
+
+	// Mock expectations
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123"}, nil).Once()
+	mockRepo.On("Quarantine", "abc123", "disabled by moderator: phishing").Return(nil)
+	mockCache.On("Delete", "short_url:abc123").Return(nil)
+	mockReports.On("ResolveByCode", "abc123").Return(int64(2), nil).Once()
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{
+		ShortCode:        "abc123",
+		QuarantinedAt:    &quarantinedAt,
+		QuarantineReason: "disabled by moderator: phishing",
+	}, nil).Once()
+
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123", QuarantinedAt: &quarantinedAt}, nil).Once()
+	mockRepo.On("ReleaseQuarantine", "abc123").Return(nil)
+	mockReports.On("ResolveByCode", "abc123").Return(int64(0), nil).Once()
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123"}, nil).Once()
+
+	// Execute
+	disabled, disableErr := service.DisableShortURL("abc123", "phishing")
+	restored, restoreErr := service.RestoreShortURL("abc123")
+
+	// Assert
+	assert.NoError(t, disableErr)
+	assert.True(t, disabled.Disabled)
+	assert.Equal(t, int64(2), disabled.ResolvedReports)
+	assert.NoError(t, restoreErr)
+	assert.False(t, restored.Disabled)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockReports.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/shortener/internal/cache"
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/geoip"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"go.uber.org/zap"
+)
+
+type AnalyticsService interface {
+	RecordClick(shortCode string, info *model.ClickInfo) error
+	GetClickStats(shortCode string) (*model.ClickStats, error)
+	GetTimeSeries(shortCode, interval string, from, to time.Time) (*model.TimeSeriesResponse, error)
+	GetBreakdown(shortCode, dimension string, limit int) (*model.BreakdownResponse, error)
+	GetPipelineMetrics() model.ClickPipelineMetrics
+}
+
+const (
+	// maxTimeSeriesPoints bounds the number of buckets returned by a single query
+	maxTimeSeriesPoints = 2000
+	// maxBreakdownItems bounds the number of values returned by a breakdown
+	maxBreakdownItems = 100
+)
+
+type analyticsService struct {
+	clickRepo  repository.ClickRepository
+	rollupRepo repository.ClickRollupRepository
+	pipeline   *ClickPipeline
+	geo        geoip.Resolver
+	cache      cache.AnalyticsCache
+	config     *config.Config
+}
+
+func NewAnalyticsService(clickRepo repository.ClickRepository, rollupRepo repository.ClickRollupRepository, pipeline *ClickPipeline, geo geoip.Resolver, cache cache.AnalyticsCache, cfg *config.Config) AnalyticsService {
+	return &analyticsService{
+		clickRepo:  clickRepo,
+		rollupRepo: rollupRepo,
+		pipeline:   pipeline,
+		geo:        geo,
+		cache:      cache,
+		config:     cfg,
+	}
+}
+
+func (s *analyticsService) RecordClick(shortCode string, info *model.ClickInfo) error {
+	userAgent := parseUserAgent(info.UserAgent)
+	location := s.geo.Lookup(info.IP)
+	click := &model.Click{
+		ShortCode:      shortCode,
+		ClickedAt:      time.Now(),
+		Referrer:       info.Referrer,
+		UserAgent:      info.UserAgent,
+		IPHash:         hashIP(s.config.App.IPHashSalt, info.IP),
+		ReferrerDomain: normalizeReferrer(info.Referrer),
+		Browser:        userAgent.Browser,
+		OS:             userAgent.OS,
+		DeviceType:     userAgent.DeviceType,
+		Country:        location.Country,
+		City:           location.City,
+	}
+
+	// Dropped clicks are counted by the pipeline metrics, the redirect must not fail
+	if !s.pipeline.Enqueue(click) {
+		logger.Debug("Click dropped, queue is full", zap.String("short_code", shortCode))
+	}
+
+	s.trackVisitor(shortCode, click)
+
+	return nil
+}
+
+func (s *analyticsService) GetClickStats(shortCode string) (*model.ClickStats, error) {
+	stats, err := s.clickRepo.GetStats(shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get click stats: %w", err)
+	}
+
+	// Unique visitors are approximated with HyperLogLogs instead of a distinct count over clicks
+	if count, err := s.cache.PFCount(visitorsKey(shortCode)); err == nil {
+		stats.UniqueVisitors = count
+	} else {
+		logger.Warn("Failed to count unique visitors", zap.Error(err), zap.String("short_code", shortCode))
+	}
+	if count, err := s.cache.PFCount(dailyVisitorsKey(shortCode, time.Now())); err == nil {
+		stats.UniqueVisitorsToday = count
+	} else {
+		logger.Warn("Failed to count today's unique visitors", zap.Error(err), zap.String("short_code", shortCode))
+	}
+
+	return stats, nil
+}
+
+func (s *analyticsService) GetTimeSeries(shortCode, interval string, from, to time.Time) (*model.TimeSeriesResponse, error) {
+	var step time.Duration
+	switch interval {
+	case model.GranularityHour:
+		step = time.Hour
+		from = from.UTC().Truncate(time.Hour)
+	case model.GranularityDay:
+		step = 24 * time.Hour
+		from = truncateToDay(from)
+	default:
+		return nil, fmt.Errorf("invalid interval")
+	}
+	to = to.UTC()
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid time range")
+	}
+	if to.Sub(from)/step > maxTimeSeriesPoints {
+		return nil, fmt.Errorf("time range too large")
+	}
+
+	rollups, err := s.rollupRepo.FindRange(shortCode, interval, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get click rollups: %w", err)
+	}
+
+	counts := make(map[time.Time]int64, len(rollups))
+	for _, rollup := range rollups {
+		counts[rollup.BucketStart.UTC()] = rollup.Clicks
+	}
+
+	// Fill empty buckets with zero so the series has no gaps
+	points := make([]model.TimeSeriesPoint, 0)
+	for bucket := from; bucket.Before(to); bucket = bucket.Add(step) {
+		points = append(points, model.TimeSeriesPoint{
+			BucketStart: bucket,
+			Clicks:      counts[bucket],
+		})
+	}
+
+	response := &model.TimeSeriesResponse{
+		ShortCode: shortCode,
+		Interval:  interval,
+		From:      from,
+		To:        to,
+		Points:    points,
+	}
+
+	return response, nil
+}
+
+func (s *analyticsService) GetBreakdown(shortCode, dimension string, limit int) (*model.BreakdownResponse, error) {
+	switch dimension {
+	case model.DimensionReferrer, model.DimensionBrowser, model.DimensionOS, model.DimensionDevice,
+		model.DimensionCountry, model.DimensionCity:
+	default:
+		return nil, fmt.Errorf("invalid dimension")
+	}
+
+	if limit <= 0 || limit > maxBreakdownItems {
+		limit = maxBreakdownItems
+	}
+
+	items, err := s.clickRepo.CountByDimension(shortCode, dimension, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get click breakdown: %w", err)
+	}
+
+	if items == nil {
+		items = []model.BreakdownItem{}
+	}
+
+	// Clicks recorded before the dimension existed have no value
+	for i := range items {
+		if items[i].Value == "" {
+			items[i].Value = unknownValue
+		}
+	}
+
+	response := &model.BreakdownResponse{
+		ShortCode: shortCode,
+		Dimension: dimension,
+		Items:     items,
+	}
+
+	return response, nil
+}
+
+func (s *analyticsService) GetPipelineMetrics() model.ClickPipelineMetrics {
+	return s.pipeline.Metrics()
+}
+
+// trackVisitor adds the visitor to the all-time and daily HyperLogLogs of the short URL
+func (s *analyticsService) trackVisitor(shortCode string, click *model.Click) {
+	if click.IPHash == "" {
+		return
+	}
+	visitor := click.IPHash + "|" + click.UserAgent
+
+	if err := s.cache.PFAdd(visitorsKey(shortCode), 0, visitor); err != nil {
+		logger.Warn("Failed to track unique visitor", zap.Error(err), zap.String("short_code", shortCode))
+		return
+	}
+
+	ttl := time.Duration(s.config.Analytics.VisitorsTTLDays) * 24 * time.Hour
+	if err := s.cache.PFAdd(dailyVisitorsKey(shortCode, click.ClickedAt), ttl, visitor); err != nil {
+		logger.Warn("Failed to track daily unique visitor", zap.Error(err), zap.String("short_code", shortCode))
+	}
+}
+
+func visitorsKey(shortCode string) string {
+	return fmt.Sprintf("visitors:%s", shortCode)
+}
+
+func dailyVisitorsKey(shortCode string, day time.Time) string {
+	return fmt.Sprintf("visitors:%s:%s", shortCode, day.UTC().Format("2006-01-02"))
+}
+
+// hashIP pseudonymizes the client IP so raw addresses are never stored
+func hashIP(salt, ip string) string {
+	if ip == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(salt + ip))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/geoip"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Click Repository
+type MockClickRepository struct {
+	mock.Mock
+}
+
+func (m *MockClickRepository) CreateBatch(clicks []*model.Click) error {
+	args := m.Called(clicks)
+	return args.Error(0)
+}
+
+func (m *MockClickRepository) GetStats(shortCode string) (*model.ClickStats, error) {
+	args := m.Called(shortCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ClickStats), args.Error(1)
+}
+
+func (m *MockClickRepository) CountByDimension(shortCode, dimension string, limit int) ([]model.BreakdownItem, error) {
+	args := m.Called(shortCode, dimension, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.BreakdownItem), args.Error(1)
+}
+
+func (m *MockClickRepository) List(filter *model.ClickFilter) ([]model.Click, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Click), args.Error(1)
+}
+
+// Mock Click Rollup Repository
+type MockClickRollupRepository struct {
+	mock.Mock
+}
+
+func (m *MockClickRollupRepository) LatestBucket(granularity string) (*time.Time, error) {
+	args := m.Called(granularity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockClickRollupRepository) RollupHourly(from, to time.Time) error {
+	args := m.Called(from, to)
+	return args.Error(0)
+}
+
+func (m *MockClickRollupRepository) RollupDaily(from, to time.Time) error {
+	args := m.Called(from, to)
+	return args.Error(0)
+}
+
+func (m *MockClickRollupRepository) FindRange(shortCode, granularity string, from, to time.Time) ([]model.ClickRollup, error) {
+	args := m.Called(shortCode, granularity, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ClickRollup), args.Error(1)
+}
+
+// MockAnalyticsCache implements cache.AnalyticsCache
+type MockAnalyticsCache struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsCache) PFAdd(key string, expiration time.Duration, elements ...string) error {
+	args := m.Called(key, expiration, elements)
+	return args.Error(0)
+}
+
+func (m *MockAnalyticsCache) PFCount(keys ...string) (int64, error) {
+	args := m.Called(keys)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Stub GeoIP resolver
+type stubGeoResolver map[string]geoip.Location
+
+func (r stubGeoResolver) Lookup(ip string) geoip.Location {
+	return r[ip]
+}
+
+func (r stubGeoResolver) Close() error {
+	return nil
+}
+
+func TestRecordClick(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	mockCache := new(MockAnalyticsCache)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			IPHashSalt: "salt",
+		},
+		Analytics: config.AnalyticsConfig{
+			VisitorsTTLDays: 90,
+		},
+	}
+
+	pipeline := NewClickPipeline(mockClickRepo, cfg)
+	geo := stubGeoResolver{"203.0.113.7": {Country: "TR", City: "Istanbul"}}
+	service := NewAnalyticsService(mockClickRepo, new(MockClickRollupRepository), pipeline, geo, mockCache, cfg)
+
+	// Test data
+	info := &model.ClickInfo{
+		Referrer:  "https://www.news.ycombinator.com/item?id=1",
+		UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		IP:        "203.0.113.7",
+	}
+
+	// Mock expectations
+	today := "visitors:abc123:" + time.Now().UTC().Format("2006-01-02")
+	mockCache.On("PFAdd", "visitors:abc123", time.Duration(0), mock.AnythingOfType("[]string")).Return(nil)
+	mockCache.On("PFAdd", today, 90*24*time.Hour, mock.AnythingOfType("[]string")).Return(nil)
+
+	// Execute
+	err := service.RecordClick("abc123", info)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), pipeline.Metrics().Enqueued)
+	recorded := <-pipeline.queue
+	assert.Equal(t, "abc123", recorded.ShortCode)
+	assert.Equal(t, info.Referrer, recorded.Referrer)
+	assert.Equal(t, info.UserAgent, recorded.UserAgent)
+	assert.Len(t, recorded.IPHash, 64)
+	assert.NotContains(t, recorded.IPHash, info.IP)
+	assert.False(t, recorded.ClickedAt.IsZero())
+	assert.Equal(t, "news.ycombinator.com", recorded.ReferrerDomain)
+	assert.Equal(t, "Safari", recorded.Browser)
+	assert.Equal(t, "iOS", recorded.OS)
+	assert.Equal(t, "mobile", recorded.DeviceType)
+	assert.Equal(t, "TR", recorded.Country)
+	assert.Equal(t, "Istanbul", recorded.City)
+
+	// Verify no synchronous database write
+	mockClickRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+	mockCache.AssertExpectations(t)
+}
+
+func TestGetClickStats(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	mockCache := new(MockAnalyticsCache)
+	cfg := &config.Config{}
+
+	service := NewAnalyticsService(mockClickRepo, new(MockClickRollupRepository), NewClickPipeline(mockClickRepo, cfg), stubGeoResolver{}, mockCache, cfg)
+
+	// Test data
+	lastClick := time.Now()
+	today := "visitors:abc123:" + time.Now().UTC().Format("2006-01-02")
+
+	// Mock expectations
+	mockClickRepo.On("GetStats", "abc123").Return(&model.ClickStats{
+		TotalClicks: 42,
+		LastClickAt: &lastClick,
+	}, nil)
+	mockCache.On("PFCount", []string{"visitors:abc123"}).Return(int64(17), nil)
+	mockCache.On("PFCount", []string{today}).Return(int64(3), nil)
+
+	// Execute
+	stats, err := service.GetClickStats("abc123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), stats.TotalClicks)
+	assert.Equal(t, int64(17), stats.UniqueVisitors)
+	assert.Equal(t, int64(3), stats.UniqueVisitorsToday)
+	assert.Equal(t, &lastClick, stats.LastClickAt)
+
+	// Verify mock calls
+	mockClickRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestGetTimeSeries_FillsEmptyBuckets(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	mockRollupRepo := new(MockClickRollupRepository)
+	cfg := &config.Config{}
+
+	service := NewAnalyticsService(mockClickRepo, mockRollupRepo, NewClickPipeline(mockClickRepo, cfg), stubGeoResolver{}, new(MockAnalyticsCache), cfg)
+
+	// Test data
+	from := time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	day1 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day3 := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	// Mock expectations
+	mockRollupRepo.On("FindRange", "abc123", model.GranularityDay, day1, to).Return([]model.ClickRollup{
+		{ShortCode: "abc123", Granularity: model.GranularityDay, BucketStart: day1, Clicks: 5},
+		{ShortCode: "abc123", Granularity: model.GranularityDay, BucketStart: day3, Clicks: 7},
+	}, nil)
+
+	// Execute
+	series, err := service.GetTimeSeries("abc123", model.GranularityDay, from, to)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, day1, series.From)
+	assert.Equal(t, []model.TimeSeriesPoint{
+		{BucketStart: day1, Clicks: 5},
+		{BucketStart: day1.AddDate(0, 0, 1), Clicks: 0},
+		{BucketStart: day3, Clicks: 7},
+		{BucketStart: day3.AddDate(0, 0, 1), Clicks: 0},
+	}, series.Points)
+
+	// Verify mock calls
+	mockRollupRepo.AssertExpectations(t)
+}
+
+func TestGetTimeSeries_InvalidParameters(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	mockRollupRepo := new(MockClickRollupRepository)
+	cfg := &config.Config{}
+
+	service := NewAnalyticsService(mockClickRepo, mockRollupRepo, NewClickPipeline(mockClickRepo, cfg), stubGeoResolver{}, new(MockAnalyticsCache), cfg)
+	now := time.Now()
+
+	// Execute & Assert
+	_, err := service.GetTimeSeries("abc123", "week", now.Add(-time.Hour), now)
+	assert.EqualError(t, err, "invalid interval")
+
+	_, err = service.GetTimeSeries("abc123", model.GranularityHour, now, now.Add(-time.Hour))
+	assert.EqualError(t, err, "invalid time range")
+
+	_, err = service.GetTimeSeries("abc123", model.GranularityHour, now.AddDate(-1, 0, 0), now)
+	assert.EqualError(t, err, "time range too large")
+
+	mockRollupRepo.AssertNotCalled(t, "FindRange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetBreakdown(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	cfg := &config.Config{}
+
+	service := NewAnalyticsService(mockClickRepo, new(MockClickRollupRepository), NewClickPipeline(mockClickRepo, cfg), stubGeoResolver{}, new(MockAnalyticsCache), cfg)
+
+	// Mock expectations
+	mockClickRepo.On("CountByDimension", "abc123", model.DimensionDevice, 100).Return([]model.BreakdownItem{
+		{Value: "mobile", Clicks: 12},
+		{Value: "", Clicks: 3},
+	}, nil)
+
+	// Execute
+	breakdown, err := service.GetBreakdown("abc123", model.DimensionDevice, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, model.DimensionDevice, breakdown.Dimension)
+	assert.Equal(t, []model.BreakdownItem{
+		{Value: "mobile", Clicks: 12},
+		{Value: "unknown", Clicks: 3},
+	}, breakdown.Items)
+
+	// Unknown dimensions never reach the repository
+	_, err = service.GetBreakdown("abc123", "planet", 10)
+	assert.EqualError(t, err, "invalid dimension")
+
+	// Verify mock calls
+	mockClickRepo.AssertExpectations(t)
+}
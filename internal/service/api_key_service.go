@@ -0,0 +1,314 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidCredentials is returned for unknown, revoked and expired credentials
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrForbidden is returned when the caller may not perform the action
+	ErrForbidden = errors.New("forbidden")
+)
+
+const (
+	// apiKeyPrefix makes keys recognizable, e.g. for secret scanners
+	apiKeyPrefix = "sk_"
+	// apiKeyBytes is the amount of randomness in a key
+	apiKeyBytes = 32
+	// apiKeyDisplayLength is how much of the key is kept to tell keys apart
+	apiKeyDisplayLength = 8
+	// lastUsedResolution limits last used updates to one write per key and interval
+	lastUsedResolution = time.Minute
+	// legacyKeyRole is the role of keys created before workspace members existed
+	legacyKeyRole = model.RoleEditor
+)
+
+// baseScopes are the scopes of keys that don't ask for specific ones
+var baseScopes = []string{model.ScopeLinksWrite, model.ScopeLinksRead, model.ScopeStatsRead}
+
+// HasScope reports whether the principal's credential carries the scope
+func HasScope(principal *model.Principal, scope string) bool {
+	return principal != nil && slices.Contains(principal.Scopes, scope)
+}
+
+// validScope reports whether scope is one of the known scopes
+func validScope(scope string) bool {
+	return scope == model.ScopeAdmin || slices.Contains(baseScopes, scope)
+}
+
+// defaultScopes returns the scopes of keys created without explicit scopes,
+// including keys created before scopes existed
+func defaultScopes(admin bool) []string {
+	scopes := slices.Clone(baseScopes)
+	if admin {
+		scopes = append(scopes, model.ScopeAdmin)
+	}
+	return scopes
+}
+
+// APIKeyService manages API keys and turns bearer tokens into principals
+type APIKeyService interface {
+	Authenticate(token string) (*model.Principal, error)
+	CreateKey(principal *model.Principal, req *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error)
+	ListKeys(principal *model.Principal) (*model.ListAPIKeysResponse, error)
+	RevokeKey(principal *model.Principal, id uint) error
+}
+
+type apiKeyService struct {
+	repo       repository.APIKeyRepository
+	workspaces repository.WorkspaceRepository
+	config     *config.Config
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, workspaces repository.WorkspaceRepository, cfg *config.Config) APIKeyService {
+	return &apiKeyService{
+		repo:       repo,
+		workspaces: workspaces,
+		config:     cfg,
+	}
+}
+
+// Authenticate accepts the bootstrap AUTH_TOKEN, when configured, and active API
+// keys. The role is read from the membership on every request, so role changes
+// and removed members take effect immediately.
+func (s *apiKeyService) Authenticate(token string) (*model.Principal, error) {
+	if token == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if bootstrap := s.config.Auth.Token; bootstrap != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(bootstrap)) == 1 {
+		return &model.Principal{Admin: true, Scopes: defaultScopes(true)}, nil
+	}
+
+	if !strings.HasPrefix(token, apiKeyPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+	key, err := s.repo.FindByHash(hashAPIKey(token))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	role := legacyKeyRole
+	if key.MemberID != "" {
+		member, err := s.workspaces.FindMember(key.WorkspaceID, key.MemberID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrInvalidCredentials
+			}
+			return nil, fmt.Errorf("failed to find workspace member: %w", err)
+		}
+		role = member.Role
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(key.ID, now); err != nil {
+			logger.Warn("Failed to update api key last use", zap.Uint("key_id", key.ID), zap.Error(err))
+		}
+	}
+
+	scopes := key.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes(key.Admin)
+	}
+	return &model.Principal{
+		WorkspaceID: key.WorkspaceID,
+		MemberID:    key.MemberID,
+		Role:        role,
+		KeyID:       key.ID,
+		Admin:       key.Admin && slices.Contains(scopes, model.ScopeAdmin),
+		Scopes:      scopes,
+	}, nil
+}
+
+// CreateKey issues a key to a workspace member. Instance admins can create keys
+// in any workspace, including admin keys. Workspace admins with the admin scope
+// issue keys to members of their workspace, everyone else only to themselves.
+// A key never gets scopes its creator's credential doesn't have.
+func (s *apiKeyService) CreateKey(principal *model.Principal, req *model.CreateAPIKeyRequest) (*model.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("invalid expiry")
+	}
+	scopes := defaultScopes(req.Admin)
+	if len(req.Scopes) > 0 {
+		scopes = slices.Clone(req.Scopes)
+		slices.Sort(scopes)
+		scopes = slices.Compact(scopes)
+	}
+	for _, scope := range scopes {
+		if !validScope(scope) {
+			return nil, fmt.Errorf("invalid scopes")
+		}
+	}
+	if req.Admin && !slices.Contains(scopes, model.ScopeAdmin) {
+		return nil, fmt.Errorf("invalid scopes")
+	}
+	for _, scope := range scopes {
+		if !HasScope(principal, scope) {
+			return nil, ErrForbidden
+		}
+	}
+
+	workspaceID := strings.TrimSpace(req.WorkspaceID)
+	if workspaceID == "" {
+		workspaceID = principal.WorkspaceID
+	}
+	memberID := strings.TrimSpace(req.MemberID)
+	if memberID == "" {
+		memberID = principal.MemberID
+	}
+	if memberID == "" {
+		return nil, fmt.Errorf("member_id is required")
+	}
+	if !principal.Admin && (workspaceID != principal.WorkspaceID || req.Admin ||
+		(memberID != principal.MemberID && !canAdministerWorkspace(principal))) {
+		return nil, ErrForbidden
+	}
+
+	member, err := s.workspaces.FindMember(workspaceID, memberID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("member not found")
+		}
+		return nil, fmt.Errorf("failed to find workspace member: %w", err)
+	}
+	// A key acts with its member's role, so minting one for an owner or
+	// another admin would let an admin escalate
+	if memberID != principal.MemberID && !canManageRole(principal, member.Role) {
+		return nil, ErrForbidden
+	}
+
+	token, err := generateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key := &model.APIKey{
+		Name:        name,
+		WorkspaceID: workspaceID,
+		MemberID:    memberID,
+		Prefix:      token[:len(apiKeyPrefix)+apiKeyDisplayLength],
+		KeyHash:     hashAPIKey(token),
+		Admin:       req.Admin,
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &model.CreateAPIKeyResponse{APIKey: *key, Key: token}, nil
+}
+
+// ListKeys returns every key for instance admins, the workspace's keys for
+// workspace admins with the admin scope and the caller's own keys otherwise
+func (s *apiKeyService) ListKeys(principal *model.Principal) (*model.ListAPIKeysResponse, error) {
+	workspaceID, memberID := principal.WorkspaceID, principal.MemberID
+	if principal.Admin {
+		workspaceID, memberID = "", ""
+	} else if canAdministerWorkspace(principal) {
+		memberID = ""
+	}
+
+	keys, err := s.repo.List(workspaceID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	if keys == nil {
+		keys = []model.APIKey{}
+	}
+	return &model.ListAPIKeysResponse{Items: keys}, nil
+}
+
+// RevokeKey revokes a key. Keys the caller can't list look missing. Like for
+// creation, the caller must be able to manage the key's member and needs every
+// scope of the key, so a leaked read-only key can't revoke the member's other
+// keys.
+func (s *apiKeyService) RevokeKey(principal *model.Principal, id uint) error {
+	key, err := s.repo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("api key not found")
+		}
+		return fmt.Errorf("failed to find api key: %w", err)
+	}
+	if !principal.Admin && (key.WorkspaceID != principal.WorkspaceID ||
+		(key.MemberID != principal.MemberID && !canAdministerWorkspace(principal))) {
+		return fmt.Errorf("api key not found")
+	}
+	if key.MemberID != principal.MemberID {
+		member, err := s.workspaces.FindMember(key.WorkspaceID, key.MemberID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to find workspace member: %w", err)
+		}
+		// Keys of removed members can be cleaned up by any workspace admin
+		if err == nil && !canManageRole(principal, member.Role) {
+			return ErrForbidden
+		}
+	}
+	scopes := key.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes(key.Admin)
+	}
+	for _, scope := range scopes {
+		if !HasScope(principal, scope) {
+			return ErrForbidden
+		}
+	}
+
+	if err := s.repo.Revoke(id); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return nil
+}
+
+// canAdministerWorkspace reports whether the principal may manage other members'
+// keys: it needs the admin role and a credential with the admin scope
+func canAdministerWorkspace(principal *model.Principal) bool {
+	return HasRole(principal, model.RoleAdmin) && HasScope(principal, model.ScopeAdmin)
+}
+
+// generateAPIKey returns a new random key such as sk_Jr4xQ...
+func generateAPIKey() (string, error) {
+	buf := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashAPIKey hashes a key for storage. Keys are random, so a fast unsalted hash
+// is enough to make a leaked table useless.
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
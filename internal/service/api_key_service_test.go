@@ -0,0 +1,339 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(key *model.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) FindByHash(keyHash string) (*model.APIKey, error) {
+	args := m.Called(keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindByID(id uint) (*model.APIKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) List(workspaceID, memberID string) ([]model.APIKey, error) {
+	args := m.Called(workspaceID, memberID)
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+
+func TestAuthenticate_BootstrapToken(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, new(MockWorkspaceRepository), &config.Config{Auth: config.AuthConfig{Token: "bootstrap"}})
+
+	// Execute
+	principal, err := service.Authenticate("bootstrap")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Principal{Admin: true, Scopes: defaultScopes(true)}, principal)
+	mockRepo.AssertNotCalled(t, "FindByHash", mock.Anything)
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	mockWorkspaces := new(MockWorkspaceRepository)
+	service := NewAPIKeyService(mockRepo, mockWorkspaces, &config.Config{})
+	token := "sk_test-key"
+	past := time.Now().Add(-time.Hour)
+	recent := time.Now()
+
+	// Mock expectations - the role is read from the membership on every request
+	mockRepo.On("FindByHash", hashAPIKey(token)).Return(&model.APIKey{ID: 7, WorkspaceID: "growth", MemberID: "alice", LastUsedAt: &past}, nil).Once()
+	mockRepo.On("TouchLastUsed", uint(7), mock.AnythingOfType("time.Time")).Return(nil).Once()
+	mockRepo.On("FindByHash", hashAPIKey(token)).Return(&model.APIKey{ID: 7, WorkspaceID: "growth", MemberID: "alice", LastUsedAt: &recent}, nil).Once()
+	mockWorkspaces.On("FindMember", "growth", "alice").Return(&model.WorkspaceMember{Role: model.RoleEditor}, nil).Once()
+	mockWorkspaces.On("FindMember", "growth", "alice").Return(&model.WorkspaceMember{Role: model.RoleViewer}, nil).Once()
+
+	// Execute
+	first, firstErr := service.Authenticate(token)
+	second, secondErr := service.Authenticate(token)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	// Keys created before scopes existed get the default scopes
+	assert.Equal(t, &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, KeyID: 7, Scopes: defaultScopes(false)}, first)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, model.RoleViewer, second.Role)
+
+	// Verify mock calls, a recent use is not written again
+	mockRepo.AssertExpectations(t)
+	mockWorkspaces.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "TouchLastUsed", 1)
+}
+
+func TestAuthenticate_Rejected(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	mockWorkspaces := new(MockWorkspaceRepository)
+	service := NewAPIKeyService(mockRepo, mockWorkspaces, &config.Config{})
+	past := time.Now().Add(-time.Minute)
+
+	// Mock expectations
+	mockRepo.On("FindByHash", hashAPIKey("sk_revoked")).Return(&model.APIKey{ID: 1, RevokedAt: &past}, nil)
+	mockRepo.On("FindByHash", hashAPIKey("sk_expired")).Return(&model.APIKey{ID: 2, ExpiresAt: &past}, nil)
+	mockRepo.On("FindByHash", hashAPIKey("sk_unknown")).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByHash", hashAPIKey("sk_removed")).Return(&model.APIKey{ID: 3, WorkspaceID: "growth", MemberID: "bob"}, nil)
+	mockWorkspaces.On("FindMember", "growth", "bob").Return(nil, gorm.ErrRecordNotFound)
+
+	// Execute & Assert
+	for _, token := range []string{"", "your-secret-token", "sk_revoked", "sk_expired", "sk_unknown", "sk_removed"} {
+		_, err := service.Authenticate(token)
+		assert.ErrorIs(t, err, ErrInvalidCredentials, token)
+	}
+	mockRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+}
+
+func TestCreateKey(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	mockWorkspaces := new(MockWorkspaceRepository)
+	service := NewAPIKeyService(mockRepo, mockWorkspaces, &config.Config{})
+	member := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, KeyID: 3, Scopes: defaultScopes(false)}
+
+	// Mock expectations
+	mockWorkspaces.On("FindMember", "growth", "alice").Return(&model.WorkspaceMember{Role: model.RoleEditor}, nil)
+	var stored *model.APIKey
+	mockRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.APIKey)
+	}).Return(nil)
+
+	// Execute
+	response, err := service.CreateKey(member, &model.CreateAPIKeyRequest{Name: "ci"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(response.Key, apiKeyPrefix))
+	assert.Equal(t, "growth", stored.WorkspaceID)
+	assert.Equal(t, "alice", stored.MemberID)
+	assert.Equal(t, hashAPIKey(response.Key), stored.KeyHash)
+	assert.True(t, strings.HasPrefix(response.Key, stored.Prefix))
+	assert.Equal(t, defaultScopes(false), stored.Scopes)
+
+	_, err = service.CreateKey(member, &model.CreateAPIKeyRequest{Name: "other", WorkspaceID: "sales"})
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = service.CreateKey(member, &model.CreateAPIKeyRequest{Name: "escalate", Admin: true})
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = service.CreateKey(member, &model.CreateAPIKeyRequest{Name: "colleague", MemberID: "bob"})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestCreateKey_WorkspaceAdmin(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	mockWorkspaces := new(MockWorkspaceRepository)
+	service := NewAPIKeyService(mockRepo, mockWorkspaces, &config.Config{})
+	admin := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleAdmin, KeyID: 3, Scopes: defaultScopes(true)}
+	// Without the admin scope a workspace admin's key only acts for the admin
+	readOnly := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleAdmin, KeyID: 4, Scopes: []string{model.ScopeLinksRead}}
+
+	// Mock expectations
+	mockWorkspaces.On("FindMember", "growth", "bob").Return(&model.WorkspaceMember{Role: model.RoleViewer}, nil)
+	mockWorkspaces.On("FindMember", "growth", "mallory").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+
+	// Execute
+	response, err := service.CreateKey(admin, &model.CreateAPIKeyRequest{Name: "bob-laptop", MemberID: "bob"})
+	_, missingErr := service.CreateKey(admin, &model.CreateAPIKeyRequest{Name: "stranger", MemberID: "mallory"})
+	_, scopeErr := service.CreateKey(readOnly, &model.CreateAPIKeyRequest{Name: "bob-dashboard", MemberID: "bob", Scopes: []string{model.ScopeLinksRead}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", response.MemberID)
+	assert.EqualError(t, missingErr, "member not found")
+	assert.ErrorIs(t, scopeErr, ErrForbidden)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestRevokeKey_Visibility(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	mockWorkspaces := new(MockWorkspaceRepository)
+	service := NewAPIKeyService(mockRepo, mockWorkspaces, &config.Config{})
+
+	// Mock expectations
+	mockRepo.On("FindByID", uint(5)).Return(&model.APIKey{ID: 5, WorkspaceID: "sales", MemberID: "carol"}, nil)
+	mockWorkspaces.On("FindMember", "sales", "carol").Return(&model.WorkspaceMember{Role: model.RoleEditor}, nil)
+	mockRepo.On("Revoke", uint(5)).Return(nil)
+
+	// Execute
+	outsiderErr := service.RevokeKey(&model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleOwner}, 5)
+	colleagueErr := service.RevokeKey(&model.Principal{WorkspaceID: "sales", MemberID: "dave", Role: model.RoleEditor}, 5)
+	unscopedAdminErr := service.RevokeKey(&model.Principal{WorkspaceID: "sales", MemberID: "erin", Role: model.RoleAdmin, Scopes: defaultScopes(false)}, 5)
+	workspaceAdminErr := service.RevokeKey(&model.Principal{WorkspaceID: "sales", MemberID: "erin", Role: model.RoleAdmin, Scopes: defaultScopes(true)}, 5)
+	adminErr := service.RevokeKey(&model.Principal{Admin: true, Scopes: defaultScopes(true)}, 5)
+
+	// Assert
+	assert.EqualError(t, outsiderErr, "api key not found")
+	assert.EqualError(t, colleagueErr, "api key not found")
+	assert.EqualError(t, unscopedAdminErr, "api key not found")
+	assert.NoError(t, workspaceAdminErr)
+	assert.NoError(t, adminErr)
+	mockRepo.AssertNumberOfCalls(t, "Revoke", 2)
+}
+
+func TestAPIKeys_AdminCannotManageOwnerOrAdminKeys(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	mockWorkspaces := new(MockWorkspaceRepository)
+	service := NewAPIKeyService(mockRepo, mockWorkspaces, &config.Config{})
+	admin := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleAdmin, Scopes: defaultScopes(true)}
+	owner := &model.Principal{WorkspaceID: "growth", MemberID: "olivia", Role: model.RoleOwner, Scopes: defaultScopes(true)}
+
+	// Mock expectations
+	mockWorkspaces.On("FindMember", "growth", "olivia").Return(&model.WorkspaceMember{Role: model.RoleOwner}, nil)
+	mockWorkspaces.On("FindMember", "growth", "bob").Return(&model.WorkspaceMember{Role: model.RoleAdmin}, nil)
+	mockWorkspaces.On("FindMember", "growth", "frank").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByID", uint(1)).Return(&model.APIKey{ID: 1, WorkspaceID: "growth", MemberID: "olivia"}, nil)
+	mockRepo.On("FindByID", uint(2)).Return(&model.APIKey{ID: 2, WorkspaceID: "growth", MemberID: "bob"}, nil)
+	// A key left behind by a removed member
+	mockRepo.On("FindByID", uint(3)).Return(&model.APIKey{ID: 3, WorkspaceID: "growth", MemberID: "frank"}, nil)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+	mockRepo.On("Revoke", mock.Anything).Return(nil)
+
+	// Execute
+	_, createOwnerErr := service.CreateKey(admin, &model.CreateAPIKeyRequest{Name: "owner-laptop", MemberID: "olivia"})
+	_, createAdminErr := service.CreateKey(admin, &model.CreateAPIKeyRequest{Name: "bob-laptop", MemberID: "bob"})
+	revokeOwnerErr := service.RevokeKey(admin, 1)
+	revokeAdminErr := service.RevokeKey(admin, 2)
+	revokeRemovedErr := service.RevokeKey(admin, 3)
+	_, ownerCreateErr := service.CreateKey(owner, &model.CreateAPIKeyRequest{Name: "bob-laptop", MemberID: "bob"})
+	ownerRevokeErr := service.RevokeKey(owner, 2)
+
+	// Assert
+	assert.ErrorIs(t, createOwnerErr, ErrForbidden)
+	assert.ErrorIs(t, createAdminErr, ErrForbidden)
+	assert.ErrorIs(t, revokeOwnerErr, ErrForbidden)
+	assert.ErrorIs(t, revokeAdminErr, ErrForbidden)
+	assert.NoError(t, revokeRemovedErr)
+	assert.NoError(t, ownerCreateErr)
+	assert.NoError(t, ownerRevokeErr)
+
+	// Verify mock calls
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	mockRepo.AssertNumberOfCalls(t, "Revoke", 2)
+}
+
+func TestHasScope(t *testing.T) {
+	dashboard := &model.Principal{WorkspaceID: "growth", Role: model.RoleOwner, Scopes: []string{model.ScopeLinksRead, model.ScopeStatsRead}}
+
+	assert.True(t, HasScope(dashboard, model.ScopeStatsRead))
+	assert.False(t, HasScope(dashboard, model.ScopeLinksWrite))
+	assert.False(t, HasScope(&model.Principal{Admin: true}, model.ScopeLinksRead))
+	assert.False(t, HasScope(nil, model.ScopeLinksRead))
+}
+
+func TestAuthenticate_ScopedKey(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, new(MockWorkspaceRepository), &config.Config{})
+	recent := time.Now()
+
+	// Mock expectations - an admin key without the admin scope
+	mockRepo.On("FindByHash", hashAPIKey("sk_dashboard")).Return(&model.APIKey{ID: 8, WorkspaceID: "growth", Admin: true, Scopes: []string{model.ScopeStatsRead}, LastUsedAt: &recent}, nil)
+
+	// Execute
+	principal, err := service.Authenticate("sk_dashboard")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, principal.Admin)
+	assert.Equal(t, []string{model.ScopeStatsRead}, principal.Scopes)
+}
+
+func TestCreateKey_Scopes(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	mockWorkspaces := new(MockWorkspaceRepository)
+	service := NewAPIKeyService(mockRepo, mockWorkspaces, &config.Config{})
+	member := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, KeyID: 3, Scopes: defaultScopes(false)}
+	dashboard := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, KeyID: 4, Scopes: []string{model.ScopeStatsRead}}
+
+	// Mock expectations
+	mockWorkspaces.On("FindMember", "growth", "alice").Return(&model.WorkspaceMember{Role: model.RoleEditor}, nil)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+
+	// Execute
+	response, err := service.CreateKey(member, &model.CreateAPIKeyRequest{Name: "dashboard", Scopes: []string{model.ScopeStatsRead, model.ScopeLinksRead, model.ScopeStatsRead}})
+	_, escalateErr := service.CreateKey(dashboard, &model.CreateAPIKeyRequest{Name: "writer", Scopes: []string{model.ScopeLinksWrite}})
+	_, defaultErr := service.CreateKey(dashboard, &model.CreateAPIKeyRequest{Name: "defaults"})
+	_, unknownErr := service.CreateKey(member, &model.CreateAPIKeyRequest{Name: "unknown", Scopes: []string{"links:delete"}})
+	_, adminErr := service.CreateKey(&model.Principal{Admin: true, Scopes: defaultScopes(true)}, &model.CreateAPIKeyRequest{
+		Name: "admin", WorkspaceID: "growth", MemberID: "alice", Admin: true, Scopes: []string{model.ScopeLinksRead},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{model.ScopeLinksRead, model.ScopeStatsRead}, response.Scopes)
+	assert.ErrorIs(t, escalateErr, ErrForbidden)
+	assert.ErrorIs(t, defaultErr, ErrForbidden)
+	assert.EqualError(t, unknownErr, "invalid scopes")
+	assert.EqualError(t, adminErr, "invalid scopes")
+
+	// Verify mock calls
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestRevokeKey_ScopeCeiling(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, new(MockWorkspaceRepository), &config.Config{})
+	dashboard := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, Scopes: []string{model.ScopeStatsRead}}
+
+	// Mock expectations - the member's CI key, a legacy key and another dashboard key
+	mockRepo.On("FindByID", uint(1)).Return(&model.APIKey{ID: 1, WorkspaceID: "growth", MemberID: "alice", Scopes: []string{model.ScopeLinksWrite}}, nil)
+	mockRepo.On("FindByID", uint(2)).Return(&model.APIKey{ID: 2, WorkspaceID: "growth", MemberID: "alice"}, nil)
+	mockRepo.On("FindByID", uint(3)).Return(&model.APIKey{ID: 3, WorkspaceID: "growth", MemberID: "alice", Scopes: []string{model.ScopeStatsRead}}, nil)
+	mockRepo.On("Revoke", uint(3)).Return(nil)
+
+	// Execute
+	writeErr := service.RevokeKey(dashboard, 1)
+	legacyErr := service.RevokeKey(dashboard, 2)
+	sameErr := service.RevokeKey(dashboard, 3)
+
+	// Assert
+	assert.ErrorIs(t, writeErr, ErrForbidden)
+	assert.ErrorIs(t, legacyErr, ErrForbidden)
+	assert.NoError(t, sameErr)
+
+	// Verify mock calls
+	mockRepo.AssertNumberOfCalls(t, "Revoke", 1)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ClickPipeline buffers clicks in a bounded in-process queue and batch-inserts
+// them from worker goroutines so redirects never wait on the database
+type ClickPipeline struct {
+	clickRepo     repository.ClickRepository
+	queue         chan *model.Click
+	batchSize     int
+	flushInterval time.Duration
+	workers       int
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	enqueued atomic.Uint64
+	dropped  atomic.Uint64
+	flushed  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+func NewClickPipeline(clickRepo repository.ClickRepository, cfg *config.Config) *ClickPipeline {
+	queueSize := cfg.Analytics.ClickQueueSize
+	if queueSize <= 0 {
+		queueSize = 10000
+	}
+	batchSize := cfg.Analytics.ClickBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	flushInterval := time.Duration(cfg.Analytics.ClickFlushIntervalMS) * time.Millisecond
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+	workers := cfg.Analytics.ClickWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return &ClickPipeline{
+		clickRepo:     clickRepo,
+		queue:         make(chan *model.Click, queueSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		workers:       workers,
+	}
+}
+
+// Start launches the worker goroutines that drain the queue
+func (p *ClickPipeline) Start() {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.run()
+	}
+}
+
+// Enqueue adds a click without blocking. It returns false and counts the click
+// as dropped when the queue is full or the pipeline is shutting down.
+func (p *ClickPipeline) Enqueue(click *model.Click) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case p.queue <- click:
+		p.enqueued.Add(1)
+		return true
+	default:
+		p.dropped.Add(1)
+		return false
+	}
+}
+
+// Shutdown stops accepting clicks and waits until the workers have flushed
+// everything still queued, or until ctx is done
+func (p *ClickPipeline) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Metrics returns a snapshot of the pipeline counters
+func (p *ClickPipeline) Metrics() model.ClickPipelineMetrics {
+	return model.ClickPipelineMetrics{
+		Enqueued:      p.enqueued.Load(),
+		Dropped:       p.dropped.Load(),
+		Flushed:       p.flushed.Load(),
+		Failed:        p.failed.Load(),
+		QueueLength:   len(p.queue),
+		QueueCapacity: cap(p.queue),
+	}
+}
+
+func (p *ClickPipeline) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*model.Click, 0, p.batchSize)
+	for {
+		select {
+		case click, ok := <-p.queue:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, click)
+			if len(batch) >= p.batchSize {
+				p.flush(batch)
+				batch = make([]*model.Click, 0, p.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.flush(batch)
+				batch = make([]*model.Click, 0, p.batchSize)
+			}
+		}
+	}
+}
+
+func (p *ClickPipeline) flush(batch []*model.Click) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := p.clickRepo.CreateBatch(batch); err != nil {
+		p.failed.Add(uint64(len(batch)))
+		logger.Error("Failed to flush click batch", zap.Error(err), zap.Int("size", len(batch)))
+		return
+	}
+
+	p.flushed.Add(uint64(len(batch)))
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClickPipeline_FlushesInBatchesOnShutdown(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	cfg := &config.Config{
+		Analytics: config.AnalyticsConfig{
+			ClickQueueSize:       100,
+			ClickBatchSize:       10,
+			ClickFlushIntervalMS: 60000,
+			ClickWorkers:         1,
+		},
+	}
+
+	var mu sync.Mutex
+	var batchSizes []int
+	mockClickRepo.On("CreateBatch", mock.AnythingOfType("[]*model.Click")).
+		Run(func(args mock.Arguments) {
+			mu.Lock()
+			defer mu.Unlock()
+			batchSizes = append(batchSizes, len(args.Get(0).([]*model.Click)))
+		}).
+		Return(nil)
+
+	pipeline := NewClickPipeline(mockClickRepo, cfg)
+	pipeline.Start()
+
+	// Execute
+	for i := 0; i < 25; i++ {
+		assert.True(t, pipeline.Enqueue(&model.Click{ShortCode: "abc123"}))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := pipeline.Shutdown(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10, 10, 5}, batchSizes)
+	metrics := pipeline.Metrics()
+	assert.Equal(t, uint64(25), metrics.Enqueued)
+	assert.Equal(t, uint64(25), metrics.Flushed)
+	assert.Equal(t, uint64(0), metrics.Dropped)
+
+	// Enqueue after shutdown is dropped instead of panicking
+	assert.False(t, pipeline.Enqueue(&model.Click{ShortCode: "abc123"}))
+	assert.Equal(t, uint64(1), pipeline.Metrics().Dropped)
+}
+
+func TestClickPipeline_FlushesOnInterval(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	cfg := &config.Config{
+		Analytics: config.AnalyticsConfig{
+			ClickQueueSize:       100,
+			ClickBatchSize:       100,
+			ClickFlushIntervalMS: 10,
+			ClickWorkers:         1,
+		},
+	}
+
+	flushed := make(chan int, 1)
+	mockClickRepo.On("CreateBatch", mock.AnythingOfType("[]*model.Click")).
+		Run(func(args mock.Arguments) { flushed <- len(args.Get(0).([]*model.Click)) }).
+		Return(nil)
+
+	pipeline := NewClickPipeline(mockClickRepo, cfg)
+	pipeline.Start()
+	defer pipeline.Shutdown(context.Background())
+
+	// Execute
+	pipeline.Enqueue(&model.Click{ShortCode: "abc123"})
+	pipeline.Enqueue(&model.Click{ShortCode: "abc123"})
+
+	// Assert
+	select {
+	case size := <-flushed:
+		assert.Equal(t, 2, size)
+	case <-time.After(2 * time.Second):
+		t.Fatal("batch was not flushed on interval")
+	}
+}
+
+func TestClickPipeline_DropsWhenFull(t *testing.T) {
+	// Setup - workers are not started so the queue cannot drain
+	mockClickRepo := new(MockClickRepository)
+	cfg := &config.Config{
+		Analytics: config.AnalyticsConfig{
+			ClickQueueSize: 2,
+		},
+	}
+
+	pipeline := NewClickPipeline(mockClickRepo, cfg)
+
+	// Execute
+	first := pipeline.Enqueue(&model.Click{ShortCode: "abc123"})
+	second := pipeline.Enqueue(&model.Click{ShortCode: "abc123"})
+	third := pipeline.Enqueue(&model.Click{ShortCode: "abc123"})
+
+	// Assert
+	assert.True(t, first)
+	assert.True(t, second)
+	assert.False(t, third)
+	metrics := pipeline.Metrics()
+	assert.Equal(t, uint64(2), metrics.Enqueued)
+	assert.Equal(t, uint64(1), metrics.Dropped)
+	assert.Equal(t, 2, metrics.QueueLength)
+	assert.Equal(t, 2, metrics.QueueCapacity)
+}
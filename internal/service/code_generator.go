@@ -0,0 +1,312 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	CodeStrategyRandom   = "random"
+	CodeStrategySequence = "sequence"
+)
+
+// base62Alphabet is used by sequence based codes
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// maxSequenceCodeLength keeps 62^length within uint64
+const maxSequenceCodeLength = 10
+
+// feistelRounds is enough for the permutation to look random, it is not meant as encryption
+const feistelRounds = 4
+
+const (
+	// collisionRateWeight is the weight of the newest observation in the
+	// moving average of the collision rate
+	collisionRateWeight = 0.05
+	// minCollisionSamples is how many codes must be observed at a length before it grows
+	minCollisionSamples = 20
+)
+
+// CodeGenerator mints candidate short codes. Callers still check candidates
+// against existing codes, since custom aliases share the same namespace, and
+// report the outcome through Observe.
+type CodeGenerator interface {
+	Generate() (string, error)
+	GenerateBatch(n int) ([]string, error)
+	// Observe records whether a generated code turned out to be taken
+	Observe(collided bool)
+	Metrics() model.CodeGeneratorMetrics
+}
+
+// NewCodeGenerator returns the generator selected by CODE_STRATEGY
+func NewCodeGenerator(repo repository.ShortURLRepository, cfg *config.Config) (CodeGenerator, error) {
+	switch cfg.App.CodeStrategy {
+	case "", CodeStrategyRandom:
+		return newRandomCodeGenerator(cfg.App.ShortCodeLength, cfg.App.ShortCodeMaxLength, cfg.App.CodeCollisionThreshold), nil
+	case CodeStrategySequence:
+		return newSequenceCodeGenerator(repo.NextCodeSequence, cfg.App.ShortCodeLength, cfg.App.CodeObfuscationKey), nil
+	default:
+		return nil, fmt.Errorf("unknown code strategy: %s", cfg.App.CodeStrategy)
+	}
+}
+
+// randomCodeGenerator encodes random bytes, the original strategy. It tracks a
+// moving average of the collision rate and adds a character once the rate
+// crosses the threshold, so creation keeps working as the keyspace fills up.
+type randomCodeGenerator struct {
+	mu        sync.Mutex
+	length    int
+	maxLength int
+	threshold float64
+
+	rate            float64
+	samples         int
+	generated       uint64
+	collisions      uint64
+	lengthIncreases uint64
+}
+
+func newRandomCodeGenerator(length, maxLength int, threshold float64) *randomCodeGenerator {
+	return &randomCodeGenerator{
+		length:    length,
+		maxLength: maxLength,
+		threshold: threshold,
+	}
+}
+
+func (g *randomCodeGenerator) Generate() (string, error) {
+	g.mu.Lock()
+	length := g.length
+	g.generated++
+	g.mu.Unlock()
+
+	// Generate random bytes
+	randomBytes := make([]byte, length)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	// Encode to base64 and clean up
+	encoded := base64.URLEncoding.EncodeToString(randomBytes)
+	shortCode := strings.ReplaceAll(encoded, "-", "")
+	shortCode = strings.ReplaceAll(shortCode, "_", "")
+
+	if len(shortCode) > length {
+		shortCode = shortCode[:length]
+	}
+
+	return shortCode, nil
+}
+
+func (g *randomCodeGenerator) GenerateBatch(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		code, err := g.Generate()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+func (g *randomCodeGenerator) Observe(collided bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	value := 0.0
+	if collided {
+		value = 1
+		g.collisions++
+	}
+	g.rate += collisionRateWeight * (value - g.rate)
+	g.samples++
+
+	if g.threshold <= 0 || g.length >= g.maxLength || g.samples < minCollisionSamples || g.rate < g.threshold {
+		return
+	}
+
+	logger.Warn("Short code collision rate too high, increasing code length",
+		zap.Int("length", g.length+1),
+		zap.Float64("collision_rate", g.rate),
+	)
+	g.length++
+	g.lengthIncreases++
+	g.rate = 0
+	g.samples = 0
+}
+
+func (g *randomCodeGenerator) Metrics() model.CodeGeneratorMetrics {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return model.CodeGeneratorMetrics{
+		Strategy:        CodeStrategyRandom,
+		Length:          g.length,
+		MaxLength:       g.maxLength,
+		Generated:       g.generated,
+		Collisions:      g.collisions,
+		CollisionRate:   g.rate,
+		LengthIncreases: g.lengthIncreases,
+	}
+}
+
+// sequenceCodeGenerator turns values of a database sequence into base62 codes.
+// The first 62^minLength values get codes of minLength characters, the next
+// 62^(minLength+1) values one character more and so on, so codes of different
+// lengths never collide. With a key each length is shuffled by a keyed
+// permutation, which keeps codes unique but makes them hard to guess.
+type sequenceCodeGenerator struct {
+	next      func(n int) ([]int64, error)
+	minLength int
+	key       []byte
+
+	length     atomic.Int64
+	generated  atomic.Uint64
+	collisions atomic.Uint64
+}
+
+func newSequenceCodeGenerator(next func(n int) ([]int64, error), minLength int, key string) *sequenceCodeGenerator {
+	if minLength <= 0 {
+		minLength = 1
+	}
+	g := &sequenceCodeGenerator{next: next, minLength: minLength}
+	if key != "" {
+		g.key = []byte(key)
+	}
+	g.length.Store(int64(minLength))
+	return g
+}
+
+func (g *sequenceCodeGenerator) Generate() (string, error) {
+	codes, err := g.GenerateBatch(1)
+	if err != nil {
+		return "", err
+	}
+	return codes[0], nil
+}
+
+func (g *sequenceCodeGenerator) GenerateBatch(n int) ([]string, error) {
+	values, err := g.next(n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code sequence: %w", err)
+	}
+
+	codes := make([]string, 0, len(values))
+	for _, value := range values {
+		code, err := g.encode(value)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		g.length.Store(int64(len(code)))
+	}
+	g.generated.Add(uint64(len(codes)))
+	return codes, nil
+}
+
+// Observe only counts collisions, sequence codes grow on their own as the sequence advances
+func (g *sequenceCodeGenerator) Observe(collided bool) {
+	if collided {
+		g.collisions.Add(1)
+	}
+}
+
+func (g *sequenceCodeGenerator) Metrics() model.CodeGeneratorMetrics {
+	return model.CodeGeneratorMetrics{
+		Strategy:   CodeStrategySequence,
+		Length:     int(g.length.Load()),
+		Generated:  g.generated.Load(),
+		Collisions: g.collisions.Load(),
+	}
+}
+
+// encode maps a sequence value (starting at 1) to its code
+func (g *sequenceCodeGenerator) encode(value int64) (string, error) {
+	if value < 1 {
+		return "", fmt.Errorf("invalid sequence value: %d", value)
+	}
+
+	// Find the code length whose block contains the value
+	index := uint64(value - 1)
+	length := g.minLength
+	for {
+		if length > maxSequenceCodeLength {
+			return "", fmt.Errorf("code sequence exhausted")
+		}
+		size := pow62(length)
+		if index < size {
+			break
+		}
+		index -= size
+		length++
+	}
+
+	if g.key != nil {
+		index = g.permute(index, pow62(length))
+	}
+
+	code := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		code[i] = base62Alphabet[index%62]
+		index /= 62
+	}
+	return string(code), nil
+}
+
+// permute shuffles x within [0, size) using a balanced Feistel network over the
+// next even number of bits, cycle walking until the result falls in range
+func (g *sequenceCodeGenerator) permute(x, size uint64) uint64 {
+	bits := uint(2)
+	for bits < 64 && uint64(1)<<bits < size {
+		bits += 2
+	}
+	half := bits / 2
+
+	for {
+		x = g.feistel(x, half)
+		if x < size {
+			return x
+		}
+	}
+}
+
+func (g *sequenceCodeGenerator) feistel(x uint64, half uint) uint64 {
+	mask := uint64(1)<<half - 1
+	left, right := x>>half, x&mask
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, left^(g.round(round, right)&mask)
+	}
+	return left<<half | right
+}
+
+func (g *sequenceCodeGenerator) round(round int, value uint64) uint64 {
+	var buf [9]byte
+	buf[0] = byte(round)
+	binary.BigEndian.PutUint64(buf[1:], value)
+
+	mac := hmac.New(sha256.New, g.key)
+	mac.Write(buf[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+func pow62(n int) uint64 {
+	result := uint64(1)
+	for i := 0; i < n; i++ {
+		result *= 62
+	}
+	return result
+}
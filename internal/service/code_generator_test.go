@@ -0,0 +1,184 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// counterSequence mimics the Postgres sequence
+func counterSequence() func(n int) ([]int64, error) {
+	var current int64
+	return func(n int) ([]int64, error) {
+		values := make([]int64, n)
+		for i := range values {
+			current++
+			values[i] = current
+		}
+		return values, nil
+	}
+}
+
+func TestSequenceCodeGenerator_Plain(t *testing.T) {
+	// Setup
+	generator := newSequenceCodeGenerator(counterSequence(), 2, "")
+
+	// Execute
+	codes, err := generator.GenerateBatch(62*62 + 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "00", codes[0])
+	assert.Equal(t, "01", codes[1])
+	assert.Equal(t, "zz", codes[62*62-1])
+	// The next block moves to three characters
+	assert.Equal(t, "000", codes[62*62])
+	assert.Equal(t, "001", codes[62*62+1])
+}
+
+func TestSequenceCodeGenerator_Obfuscated(t *testing.T) {
+	// Setup
+	generator := newSequenceCodeGenerator(counterSequence(), 2, "secret")
+	plain := newSequenceCodeGenerator(counterSequence(), 2, "")
+
+	// Execute
+	codes, err := generator.GenerateBatch(62*62 + 100)
+	plainCodes, _ := plain.GenerateBatch(10)
+
+	// Assert: every code is unique, has the block length and is not the plain counter
+	assert.NoError(t, err)
+	seen := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+		if i < 62*62 {
+			assert.Len(t, code, 2)
+		} else {
+			assert.Len(t, code, 3)
+		}
+	}
+	assert.NotEqual(t, plainCodes, codes[:10])
+
+	// The same key always yields the same codes
+	again, _ := newSequenceCodeGenerator(counterSequence(), 2, "secret").GenerateBatch(10)
+	assert.Equal(t, codes[:10], again)
+
+	// A different key yields different codes
+	other, _ := newSequenceCodeGenerator(counterSequence(), 2, "other").GenerateBatch(10)
+	assert.NotEqual(t, codes[:10], other)
+}
+
+func TestSequenceCodeGenerator_LargeValues(t *testing.T) {
+	// Setup
+	values := []int64{1, 1 << 40, 1 << 55}
+	generator := newSequenceCodeGenerator(func(n int) ([]int64, error) {
+		return values[:n], nil
+	}, 6, "secret")
+
+	// Execute
+	codes, err := generator.GenerateBatch(len(values))
+
+	// Assert
+	assert.NoError(t, err)
+	for _, code := range codes {
+		assert.Regexp(t, `^[0-9A-Za-z]{6,10}$`, code)
+	}
+}
+
+func TestNewCodeGenerator(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+
+	// Execute & Assert
+	generator, err := NewCodeGenerator(mockRepo, &config.Config{App: config.AppConfig{ShortCodeLength: 6}})
+	assert.NoError(t, err)
+	code, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Len(t, code, 6)
+
+	generator, err = NewCodeGenerator(mockRepo, &config.Config{App: config.AppConfig{CodeStrategy: CodeStrategySequence, ShortCodeLength: 6}})
+	assert.NoError(t, err)
+	mockRepo.On("NextCodeSequence", 1).Return([]int64{64}, nil)
+	code, err = generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, "000011", code)
+
+	_, err = NewCodeGenerator(mockRepo, &config.Config{App: config.AppConfig{CodeStrategy: "uuid"}})
+	assert.Error(t, err)
+}
+
+func TestRandomCodeGenerator_GrowsOnCollisions(t *testing.T) {
+	// Setup
+	assert.NoError(t, logger.Init("test"))
+	generator := newRandomCodeGenerator(4, 5, 0.1)
+
+	// Execute: occasional collisions keep the length
+	for i := 0; i < 100; i++ {
+		generator.Observe(i%50 == 0)
+	}
+	assert.Equal(t, 4, generator.Metrics().Length)
+
+	// Execute: a run of collisions grows it
+	for i := 0; i < minCollisionSamples; i++ {
+		generator.Observe(true)
+	}
+
+	// Assert
+	metrics := generator.Metrics()
+	assert.Equal(t, 5, metrics.Length)
+	assert.Equal(t, uint64(1), metrics.LengthIncreases)
+	code, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Len(t, code, 5)
+
+	// The maximum length is never exceeded
+	for i := 0; i < 10*minCollisionSamples; i++ {
+		generator.Observe(true)
+	}
+	assert.Equal(t, 5, generator.Metrics().Length)
+}
+
+func TestCreateShortURL_ObservesCollisions(t *testing.T) {
+	// Setup
+	repo := newMemoryShortURLRepository()
+	repo.codes["taken"] = "https://example.com"
+	generator := &scriptedCodeGenerator{codes: []string{"taken", "free"}}
+	service := newConcurrencyTestService(repo, generator)
+
+	// Execute
+	response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "https://example.com/new"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "free", response.ShortCode)
+	assert.Equal(t, []bool{true, false}, generator.observed)
+}
+
+// scriptedCodeGenerator returns the given codes in order and records observations
+type scriptedCodeGenerator struct {
+	codes    []string
+	observed []bool
+}
+
+func (g *scriptedCodeGenerator) Generate() (string, error) {
+	code := g.codes[0]
+	g.codes = g.codes[1:]
+	return code, nil
+}
+
+func (g *scriptedCodeGenerator) GenerateBatch(n int) ([]string, error) {
+	codes := g.codes[:n]
+	g.codes = g.codes[n:]
+	return codes, nil
+}
+
+func (g *scriptedCodeGenerator) Observe(collided bool) {
+	g.observed = append(g.observed, collided)
+}
+
+func (g *scriptedCodeGenerator) Metrics() model.CodeGeneratorMetrics {
+	return model.CodeGeneratorMetrics{}
+}
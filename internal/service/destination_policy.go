@@ -0,0 +1,335 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"go.uber.org/zap"
+	"golang.org/x/net/idna"
+)
+
+// ErrDestinationNotAllowed is returned, wrapped with the reason, for destinations
+// rejected by the destination policy
+var ErrDestinationNotAllowed = errors.New("destination not allowed")
+
+// DestinationPolicy decides whether a normalized destination may be shortened
+type DestinationPolicy interface {
+	Check(destination string) error
+}
+
+// domainLists is one loaded version of the allow and deny lists
+type domainLists struct {
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+// listFile remembers what was loaded so unchanged files are not parsed again
+type listFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// DomainPolicy rejects unsafe schemes, private network hosts and our own domain
+// and its subdomains, and applies domain allow and deny lists read from files.
+// The files are polled and reloaded when they change; a broken file keeps the
+// previous lists in effect.
+type DomainPolicy struct {
+	schemes      map[string]bool
+	allowPrivate bool
+	ownHost      string
+	interval     time.Duration
+
+	allowFile listFile
+	denyFile  listFile
+	lists     atomic.Pointer[domainLists]
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewDomainPolicy(cfg *config.Config) (*DomainPolicy, error) {
+	interval := time.Duration(cfg.URLs.PolicyReloadSec) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	p := &DomainPolicy{
+		schemes:      make(map[string]bool),
+		allowPrivate: cfg.URLs.AllowPrivateDestinations,
+		interval:     interval,
+		allowFile:    listFile{path: cfg.URLs.AllowlistFile},
+		denyFile:     listFile{path: cfg.URLs.DenylistFile},
+		stop:         make(chan struct{}),
+	}
+	for _, scheme := range cfg.URLs.AllowedSchemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+	if baseURL, err := url.Parse(cfg.App.BaseURL); err == nil {
+		p.ownHost = strings.ToLower(baseURL.Hostname())
+	}
+
+	if _, err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Start polls the list files for changes until Stop is called
+func (p *DomainPolicy) Start() {
+	if p.allowFile.path == "" && p.denyFile.path == "" {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				changed, err := p.reload()
+				if err != nil {
+					logger.Error("Failed to reload destination lists, keeping previous lists", zap.Error(err))
+				} else if changed {
+					lists := p.lists.Load()
+					logger.Info("Destination lists reloaded",
+						zap.Int("allowed_domains", len(lists.allow)),
+						zap.Int("denied_domains", len(lists.deny)),
+					)
+				}
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the reload loop
+func (p *DomainPolicy) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func (p *DomainPolicy) Check(destination string) error {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return fmt.Errorf("invalid url")
+	}
+
+	if !p.schemes[strings.ToLower(parsed.Scheme)] {
+		return fmt.Errorf("%w: scheme %q is not allowed", ErrDestinationNotAllowed, parsed.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrDestinationNotAllowed)
+	}
+	if p.ownHost != "" && (host == p.ownHost || strings.HasSuffix(host, "."+p.ownHost)) {
+		return fmt.Errorf("%w: links to this service are not allowed", ErrDestinationNotAllowed)
+	}
+	if !p.allowPrivate && isPrivateHost(host) {
+		return fmt.Errorf("%w: private network hosts are not allowed", ErrDestinationNotAllowed)
+	}
+
+	lists := p.lists.Load()
+	if matchesDomain(host, lists.deny) {
+		return fmt.Errorf("%w: domain is blocked", ErrDestinationNotAllowed)
+	}
+	if len(lists.allow) > 0 && !matchesDomain(host, lists.allow) {
+		return fmt.Errorf("%w: domain is not on the allowlist", ErrDestinationNotAllowed)
+	}
+
+	return nil
+}
+
+// reload reads the list files when they changed since the last load
+func (p *DomainPolicy) reload() (bool, error) {
+	allowChanged, err := p.allowFile.changed()
+	if err != nil {
+		return false, err
+	}
+	denyChanged, err := p.denyFile.changed()
+	if err != nil {
+		return false, err
+	}
+	if p.lists.Load() != nil && !allowChanged && !denyChanged {
+		return false, nil
+	}
+
+	allow, allowStat, err := readDomainList(p.allowFile.path)
+	if err != nil {
+		return false, err
+	}
+	deny, denyStat, err := readDomainList(p.denyFile.path)
+	if err != nil {
+		return false, err
+	}
+
+	p.allowFile.update(allowStat)
+	p.denyFile.update(denyStat)
+	p.lists.Store(&domainLists{allow: allow, deny: deny})
+	return true, nil
+}
+
+func (f *listFile) changed() (bool, error) {
+	if f.path == "" {
+		return false, nil
+	}
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", f.path, err)
+	}
+	return !info.ModTime().Equal(f.modTime) || info.Size() != f.size, nil
+}
+
+func (f *listFile) update(info os.FileInfo) {
+	if info != nil {
+		f.modTime = info.ModTime()
+		f.size = info.Size()
+	}
+}
+
+// readDomainList parses one domain per line; blank lines and # comments are skipped
+func readDomainList(path string) (map[string]bool, os.FileInfo, error) {
+	domains := make(map[string]bool)
+	if path == "" {
+		return domains, nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := scanner.Text()
+		if i := strings.IndexByte(entry, '#'); i >= 0 {
+			entry = entry[:i]
+		}
+		entry = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(entry)), "*.")
+		entry = strings.TrimSuffix(entry, ".")
+		if entry == "" {
+			continue
+		}
+		ascii, err := idna.Lookup.ToASCII(entry)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: invalid domain %q", path, line, entry)
+		}
+		domains[ascii] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return domains, info, nil
+}
+
+// matchesDomain reports whether host or one of its parent domains is in the set
+func matchesDomain(host string, domains map[string]bool) bool {
+	for {
+		if domains[host] {
+			return true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return false
+		}
+		host = host[i+1:]
+	}
+}
+
+// isPrivateHost reports localhost names and loopback, private, link local and
+// unspecified addresses, including the numeric IPv4 forms browsers accept.
+// Names are not resolved, so public names pointing at private addresses pass.
+func isPrivateHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip = parseLooseIPv4(host)
+	}
+	if ip == nil {
+		return false
+	}
+
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast()
+}
+
+// parseLooseIPv4 parses IPv4 hosts the way the WHATWG URL standard does, so
+// http://127.1/, http://0177.0.0.1/, http://0x7f.1/ and http://2130706433/ are
+// all recognized as 127.0.0.1. A host has one to four parts, each decimal, octal
+// with a leading 0 or hex with 0x; the last part fills the remaining bytes.
+func parseLooseIPv4(host string) net.IP {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+
+	var n uint64
+	for i, part := range parts {
+		value, ok := parseIPv4Part(part)
+		if !ok {
+			return nil
+		}
+		if i < len(parts)-1 {
+			if value > 255 {
+				return nil
+			}
+			n |= value << (8 * (3 - i))
+			continue
+		}
+		// The last part covers every byte not given by the earlier parts
+		if value >= 1<<(8*(4-i)) {
+			return nil
+		}
+		n |= value
+	}
+	return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// parseIPv4Part parses one part of a loose IPv4 host
+func parseIPv4Part(part string) (uint64, bool) {
+	base := 10
+	switch {
+	case strings.HasPrefix(part, "0x") || strings.HasPrefix(part, "0X"):
+		part, base = part[2:], 16
+		if part == "" {
+			return 0, true
+		}
+	case len(part) > 1 && part[0] == '0':
+		part, base = part[1:], 8
+	}
+	if part == "" || part[0] == '+' || part[0] == '-' {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(part, base, 32)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
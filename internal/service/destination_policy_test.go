@@ -0,0 +1,172 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPolicyConfig() *config.Config {
+	return &config.Config{
+		App: config.AppConfig{BaseURL: "https://sho.rt"},
+		URLs: config.URLConfig{
+			AllowedSchemes: []string{"http", "https"},
+		},
+	}
+}
+
+func TestDomainPolicy_Check(t *testing.T) {
+	// Setup
+	policy, err := NewDomainPolicy(newTestPolicyConfig())
+	assert.NoError(t, err)
+
+	allowed := []string{
+		"https://example.com/path",
+		"http://8.8.8.8/",
+		"https://sub.sho.rt.example.com/",
+	}
+	rejected := []string{
+		"javascript:alert(1)",
+		"data:text/html;base64,PHNjcmlwdD4=",
+		"file:///etc/passwd",
+		"ftp://example.com/file",
+		"http://localhost:8080/admin",
+		"http://api.localhost/",
+		"http://127.0.0.1/",
+		"http://10.1.2.3/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/",
+		"http://[fd00::1]/",
+		"http://2130706433/",
+		"http://0x7f000001/",
+		"http://127.1/",
+		"http://10.1/",
+		"http://0177.0.0.1/",
+		"http://0x7f.0.0.1/",
+		"http://0xa.0250.1/",
+		"https://sho.rt/abc123",
+		"https://www.sho.rt/abc123",
+	}
+
+	// Execute & Assert
+	for _, destination := range allowed {
+		assert.NoError(t, policy.Check(destination), destination)
+	}
+	for _, destination := range rejected {
+		assert.ErrorIs(t, policy.Check(destination), ErrDestinationNotAllowed, destination)
+	}
+}
+
+func TestDomainPolicy_Lists(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	allowFile := filepath.Join(dir, "allow.txt")
+	denyFile := filepath.Join(dir, "deny.txt")
+	assert.NoError(t, os.WriteFile(allowFile, []byte("# partners\nexample.com\n*.partner.org\n"), 0o644))
+	assert.NoError(t, os.WriteFile(denyFile, []byte("bad.example.com # phishing\n"), 0o644))
+
+	cfg := newTestPolicyConfig()
+	cfg.URLs.AllowlistFile = allowFile
+	cfg.URLs.DenylistFile = denyFile
+	policy, err := NewDomainPolicy(cfg)
+	assert.NoError(t, err)
+
+	// Assert: allowlisted domains and their subdomains pass, denylist wins
+	assert.NoError(t, policy.Check("https://example.com/"))
+	assert.NoError(t, policy.Check("https://www.example.com/"))
+	assert.NoError(t, policy.Check("https://cdn.partner.org/"))
+	assert.ErrorIs(t, policy.Check("https://bad.example.com/"), ErrDestinationNotAllowed)
+	assert.ErrorIs(t, policy.Check("https://other.com/"), ErrDestinationNotAllowed)
+
+	// Execute: the denylist changes on disk
+	assert.NoError(t, os.WriteFile(denyFile, []byte("www.example.com\n"), 0o644))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(denyFile, future, future))
+	changed, err := policy.reload()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, policy.Check("https://bad.example.com/"))
+	assert.ErrorIs(t, policy.Check("https://www.example.com/"), ErrDestinationNotAllowed)
+
+	// A broken file keeps the previous lists
+	assert.NoError(t, os.WriteFile(denyFile, []byte("not a domain!\n"), 0o644))
+	past := time.Now().Add(-time.Minute)
+	assert.NoError(t, os.Chtimes(denyFile, past, past))
+	_, err = policy.reload()
+	assert.Error(t, err)
+	assert.ErrorIs(t, policy.Check("https://www.example.com/"), ErrDestinationNotAllowed)
+
+	// Unchanged files are not reloaded
+	assert.NoError(t, os.WriteFile(denyFile, []byte("www.example.com\n"), 0o644))
+	assert.NoError(t, os.Chtimes(denyFile, future, future))
+	changed, err = policy.reload()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestDomainPolicy_MissingFile(t *testing.T) {
+	// Setup
+	cfg := newTestPolicyConfig()
+	cfg.URLs.DenylistFile = filepath.Join(t.TempDir(), "missing.txt")
+
+	// Execute
+	_, err := NewDomainPolicy(cfg)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestCreateShortURL_DestinationPolicy(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := newTestPolicyConfig()
+	policy, err := NewDomainPolicy(cfg)
+	assert.NoError(t, err)
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: 6}, policy, noopScanner{}, cfg)
+
+	// Execute
+	_, err = service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "javascript:alert(document.cookie)"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDestinationNotAllowed)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestParseLooseIPv4(t *testing.T) {
+	tests := map[string]string{
+		"127.0.0.1":      "127.0.0.1",
+		"127.1":          "127.0.0.1",
+		"10.1":           "10.0.0.1",
+		"192.168.257":    "192.168.1.1",
+		"0177.0.0.1":     "127.0.0.1",
+		"0x7f.0.0.1":     "127.0.0.1",
+		"0x7f.1":         "127.0.0.1",
+		"0X7F.000.0x0.1": "127.0.0.1",
+		"2130706433":     "127.0.0.1",
+		"0x7f000001":     "127.0.0.1",
+		"017700000001":   "127.0.0.1",
+		"0x":             "0.0.0.0",
+		"0":              "0.0.0.0",
+	}
+	for host, want := range tests {
+		ip := parseLooseIPv4(host)
+		if assert.NotNil(t, ip, host) {
+			assert.Equal(t, want, ip.String(), host)
+		}
+	}
+
+	for _, host := range []string{"example.com", "1.2.3.4.5", "256.0.0.1", "1.2.65536", "1.2.3.256", "08.0.0.1", "0x7g.0.0.1", "1..2", "+1.2.3.4", "4294967296", ""} {
+		assert.Nil(t, parseLooseIPv4(host), host)
+	}
+}
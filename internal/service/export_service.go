@@ -0,0 +1,224 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+)
+
+// exportChunkSize is how many rows are loaded and written per query
+const exportChunkSize = 1000
+
+var (
+	shortURLExportColumns = []string{"code", "destination", "expires_at", "created_at", "click_count"}
+	clickExportColumns    = []string{
+		"id", "short_code", "clicked_at", "referrer", "referrer_domain", "user_agent",
+		"browser", "os", "device_type", "country", "city", "ip_hash",
+	}
+)
+
+// ExportService streams links and raw clicks in chunks so large exports never
+// have to fit in memory
+type ExportService interface {
+	ExportShortURLs(w io.Writer, format string, filter *model.ShortURLFilter) error
+	ExportClicks(w io.Writer, format string, filter *model.ClickFilter) error
+}
+
+type exportService struct {
+	urlRepo   repository.ShortURLRepository
+	clickRepo repository.ClickRepository
+}
+
+func NewExportService(urlRepo repository.ShortURLRepository, clickRepo repository.ClickRepository) ExportService {
+	return &exportService{
+		urlRepo:   urlRepo,
+		clickRepo: clickRepo,
+	}
+}
+
+// ExportShortURLs writes matching links oldest first, using the same columns as
+// the import so an export can be imported elsewhere
+func (s *exportService) ExportShortURLs(w io.Writer, format string, filter *model.ShortURLFilter) error {
+	switch filter.Status {
+	case "", model.StatusActive, model.StatusExpired, model.StatusQuarantined:
+	default:
+		return fmt.Errorf("invalid status")
+	}
+	writer, err := newExportWriter(w, format, shortURLExportColumns)
+	if err != nil {
+		return err
+	}
+
+	filter.Sort = model.SortCreatedAt
+	filter.Descending = false
+	filter.Limit = exportChunkSize
+	for {
+		shortURLs, err := s.urlRepo.List(filter)
+		if err != nil {
+			return fmt.Errorf("failed to load short URLs: %w", err)
+		}
+
+		for _, shortURL := range shortURLs {
+			row := model.ShortURLExportRow{
+				Code:        shortURL.ShortCode,
+				Destination: shortURL.OriginalURL,
+				ExpiresAt:   shortURL.ExpiresAt,
+				CreatedAt:   shortURL.CreatedAt,
+				ClickCount:  shortURL.ClickCount,
+			}
+			err := writer.write(row, []string{
+				row.Code,
+				row.Destination,
+				formatExportTime(row.ExpiresAt),
+				formatExportTime(&row.CreatedAt),
+				strconv.FormatInt(row.ClickCount, 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := writer.flush(); err != nil {
+			return err
+		}
+
+		if len(shortURLs) < exportChunkSize {
+			return nil
+		}
+		filter.After = model.NewShortURLCursor(&shortURLs[len(shortURLs)-1], filter.Sort, filter.Descending)
+	}
+}
+
+// ExportClicks writes matching raw clicks in ID order
+func (s *exportService) ExportClicks(w io.Writer, format string, filter *model.ClickFilter) error {
+	writer, err := newExportWriter(w, format, clickExportColumns)
+	if err != nil {
+		return err
+	}
+
+	filter.Limit = exportChunkSize
+	for {
+		clicks, err := s.clickRepo.List(filter)
+		if err != nil {
+			return fmt.Errorf("failed to load clicks: %w", err)
+		}
+
+		for _, click := range clicks {
+			row := model.ClickExportRow{
+				ID:             click.ID,
+				ShortCode:      click.ShortCode,
+				ClickedAt:      click.ClickedAt,
+				Referrer:       click.Referrer,
+				ReferrerDomain: click.ReferrerDomain,
+				UserAgent:      click.UserAgent,
+				Browser:        click.Browser,
+				OS:             click.OS,
+				DeviceType:     click.DeviceType,
+				Country:        click.Country,
+				City:           click.City,
+				IPHash:         click.IPHash,
+			}
+			err := writer.write(row, []string{
+				strconv.FormatUint(uint64(row.ID), 10),
+				row.ShortCode,
+				formatExportTime(&row.ClickedAt),
+				row.Referrer,
+				row.ReferrerDomain,
+				row.UserAgent,
+				row.Browser,
+				row.OS,
+				row.DeviceType,
+				row.Country,
+				row.City,
+				row.IPHash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := writer.flush(); err != nil {
+			return err
+		}
+
+		if len(clicks) < exportChunkSize {
+			return nil
+		}
+		filter.AfterID = clicks[len(clicks)-1].ID
+	}
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// exportWriter encodes rows as CSV or NDJSON and pushes them to the client
+// after every chunk
+type exportWriter struct {
+	out     io.Writer
+	csv     *csv.Writer
+	columns []string
+	encoder *json.Encoder
+}
+
+func newExportWriter(w io.Writer, format string, columns []string) (*exportWriter, error) {
+	writer := &exportWriter{out: w, columns: columns}
+	switch format {
+	case model.FormatCSV:
+		writer.csv = csv.NewWriter(w)
+		if err := writer.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	case model.FormatNDJSON:
+		writer.encoder = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("invalid format")
+	}
+	return writer, nil
+}
+
+// write takes the row both as a JSON value and as CSV fields
+func (w *exportWriter) write(row interface{}, fields []string) error {
+	if w.csv != nil {
+		for i, field := range fields {
+			// Codes only hold URL safe characters that can't form a working
+			// formula, and must stay as they are to be imported again
+			if w.columns[i] != "code" && w.columns[i] != "short_code" {
+				fields[i] = escapeCSVFormula(field)
+			}
+		}
+		return w.csv.Write(fields)
+	}
+	return w.encoder.Encode(row)
+}
+
+// escapeCSVFormula prefixes cells that spreadsheets would evaluate as a formula
+// with a quote. Referrers, user agents and destinations come from visitors.
+func escapeCSVFormula(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	// Send buffered bytes as a chunk when writing to an HTTP response
+	if flusher, ok := w.out.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportShortURLs_CSVChunks(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	service := NewExportService(mockRepo, new(MockClickRepository))
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+	firstPage := make([]model.ShortURL, exportChunkSize)
+	for i := range firstPage {
+		firstPage[i] = model.ShortURL{ID: uint(i + 1), ShortCode: fmt.Sprintf("c%d", i), OriginalURL: "https://example.com", CreatedAt: createdAt}
+	}
+	secondPage := []model.ShortURL{
+		{ID: exportChunkSize + 1, ShortCode: "last", OriginalURL: "https://example.com/last", CreatedAt: createdAt, ExpiresAt: &expiresAt, ClickCount: 7},
+	}
+
+	// Mock expectations
+	mockRepo.On("List", mock.MatchedBy(func(f *model.ShortURLFilter) bool {
+		return f.After == nil
+	})).Return(firstPage, nil).Once()
+	mockRepo.On("List", mock.MatchedBy(func(f *model.ShortURLFilter) bool {
+		return f.After != nil && f.After.ID == exportChunkSize && f.Sort == model.SortCreatedAt && !f.Descending
+	})).Return(secondPage, nil).Once()
+
+	// Execute
+	var out bytes.Buffer
+	err := service.ExportShortURLs(&out, model.FormatCSV, &model.ShortURLFilter{Domain: "example"})
+
+	// Assert
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, exportChunkSize+2)
+	assert.Equal(t, "code,destination,expires_at,created_at,click_count", lines[0])
+	assert.Equal(t, "last,https://example.com/last,2024-05-02T12:00:00Z,2024-05-01T12:00:00Z,7", lines[len(lines)-1])
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportClicks_NDJSON(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	service := NewExportService(new(MockShortURLRepository), mockClickRepo)
+
+	clickedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clicks := []model.Click{
+		{ID: 10, ShortCode: "abc123", ClickedAt: clickedAt, ReferrerDomain: "twitter.com", Country: "TR"},
+		{ID: 11, ShortCode: "abc123", ClickedAt: clickedAt, ReferrerDomain: "direct"},
+	}
+
+	// Mock expectations
+	mockClickRepo.On("List", mock.MatchedBy(func(f *model.ClickFilter) bool {
+		return f.ShortCode == "abc123" && f.AfterID == 0 && f.Limit == exportChunkSize
+	})).Return(clicks, nil)
+
+	// Execute
+	var out bytes.Buffer
+	err := service.ExportClicks(&out, model.FormatNDJSON, &model.ClickFilter{ShortCode: "abc123"})
+
+	// Assert
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var row model.ClickExportRow
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &row))
+	assert.Equal(t, uint(10), row.ID)
+	assert.Equal(t, "twitter.com", row.ReferrerDomain)
+	assert.Equal(t, "TR", row.Country)
+
+	// Verify mock calls
+	mockClickRepo.AssertExpectations(t)
+}
+
+func TestExport_InvalidParameters(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	service := NewExportService(mockRepo, new(MockClickRepository))
+
+	// Execute & Assert
+	var out bytes.Buffer
+	err := service.ExportShortURLs(&out, "xlsx", &model.ShortURLFilter{})
+	assert.EqualError(t, err, "invalid format")
+
+	err = service.ExportShortURLs(&out, model.FormatCSV, &model.ShortURLFilter{Status: "paused"})
+	assert.EqualError(t, err, "invalid status")
+
+	err = service.ExportClicks(&out, "xlsx", &model.ClickFilter{})
+	assert.EqualError(t, err, "invalid format")
+
+	assert.Zero(t, out.Len())
+	mockRepo.AssertNotCalled(t, "List", mock.Anything)
+}
+
+func TestExportClicks_CSVEscapesFormulas(t *testing.T) {
+	// Setup
+	mockClickRepo := new(MockClickRepository)
+	service := NewExportService(new(MockShortURLRepository), mockClickRepo)
+
+	clickedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clicks := []model.Click{
+		{ID: 10, ShortCode: "abc123", ClickedAt: clickedAt, Referrer: "=HYPERLINK(\"https://evil.example\")", UserAgent: "@SUM(A1)", Browser: "-2+3", OS: "+1", DeviceType: "\tcmd"},
+	}
+
+	// Mock expectations
+	mockClickRepo.On("List", mock.Anything).Return(clicks, nil)
+
+	// Execute
+	var out bytes.Buffer
+	err := service.ExportClicks(&out, model.FormatCSV, &model.ClickFilter{ShortCode: "abc123"})
+
+	// Assert
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, `10,abc123,2024-05-01T12:00:00Z,"'=HYPERLINK(""https://evil.example"")",,'@SUM(A1),'-2+3,'+1,'	cmd,,,`, lines[1])
+}
+
+func TestExportShortURLs_CSVRoundTrip(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	exportService := NewExportService(mockRepo, new(MockClickRepository))
+	importService := NewImportService(mockRepo, allowAllPolicy{}, noopScanner{}, &config.Config{})
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Mock expectations - an alias starting with a dash is exported unchanged
+	mockRepo.On("List", mock.Anything).Return([]model.ShortURL{
+		{ID: 1, ShortCode: "-spring-sale", OriginalURL: "https://example.com/sale", CreatedAt: createdAt},
+	}, nil).Once()
+	mockRepo.On("FindExistingCodes", []string{"-spring-sale"}).Return([]string{}, nil)
+	var stored []*model.ShortURL
+	mockRepo.On("CreateBatch", mock.AnythingOfType("[]*model.ShortURL")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).([]*model.ShortURL)
+	}).Return(nil)
+
+	// Execute
+	var out bytes.Buffer
+	exportErr := exportService.ExportShortURLs(&out, model.FormatCSV, &model.ShortURLFilter{})
+	report, importErr := importService.Import(&out, model.FormatCSV, "growth")
+
+	// Assert
+	assert.NoError(t, exportErr)
+	assert.NoError(t, importErr)
+	assert.Equal(t, 1, report.Imported)
+	if assert.Len(t, stored, 1) {
+		assert.Equal(t, "-spring-sale", stored[0].ShortCode)
+		assert.Equal(t, createdAt, stored[0].CreatedAt)
+	}
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+)
+
+const (
+	// importChunkSize is how many rows are checked and inserted together
+	importChunkSize = 500
+	// maxImportErrors caps the row errors listed in a report
+	maxImportErrors = 1000
+	// maxImportCodeLength matches the short_code column size
+	maxImportCodeLength = 64
+	// maxImportLineSize bounds a single NDJSON line
+	maxImportLineSize = 1 << 20
+)
+
+// ImportService loads links exported from other shorteners, keeping their codes.
+// Imported links belong to the given workspace; without one only admins can manage them.
+type ImportService interface {
+	Import(r io.Reader, format, workspaceID string) (*model.ImportReport, error)
+}
+
+type importService struct {
+	repo       repository.ShortURLRepository
+	normalizer *urlNormalizer
+	policy     DestinationPolicy
+	scanner    URLScanner
+	config     *config.Config
+}
+
+func NewImportService(repo repository.ShortURLRepository, policy DestinationPolicy, scanner URLScanner, cfg *config.Config) ImportService {
+	return &importService{
+		repo:       repo,
+		normalizer: newURLNormalizer(cfg),
+		policy:     policy,
+		scanner:    scanner,
+		config:     cfg,
+	}
+}
+
+// importRow is a record together with its position in the source file
+type importRow struct {
+	line   int
+	record model.ImportRecord
+}
+
+// importReader yields one record at a time. A row level problem is returned as
+// an *importRowError so reading can continue with the next row.
+type importReader interface {
+	next() (*importRow, error)
+}
+
+type importRowError struct {
+	line   int
+	reason string
+}
+
+func (e *importRowError) Error() string {
+	return e.reason
+}
+
+// Import streams the file in chunks. Each chunk is committed on its own, so rows
+// already imported stay imported if a later chunk fails.
+func (s *importService) Import(r io.Reader, format, workspaceID string) (*model.ImportReport, error) {
+	var reader importReader
+	switch format {
+	case model.FormatCSV:
+		csvReader, err := newCSVImportReader(r)
+		if err != nil {
+			return nil, err
+		}
+		reader = csvReader
+	case model.FormatNDJSON:
+		reader = newNDJSONImportReader(r)
+	default:
+		return nil, fmt.Errorf("invalid format")
+	}
+
+	report := &model.ImportReport{Errors: []model.ImportRowError{}}
+	chunk := make([]*importRow, 0, importChunkSize)
+
+	for {
+		row, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var rowErr *importRowError
+			if !errors.As(err, &rowErr) {
+				return report, fmt.Errorf("failed to read import file: %w", err)
+			}
+			report.TotalRows++
+			report.Invalid++
+			s.addError(report, rowErr.line, "", rowErr.reason)
+			continue
+		}
+
+		report.TotalRows++
+		if reason := s.validateRecord(&row.record); reason != "" {
+			report.Invalid++
+			s.addError(report, row.line, row.record.Code, reason)
+			continue
+		}
+
+		chunk = append(chunk, row)
+		if len(chunk) == importChunkSize {
+			if err := s.flush(chunk, workspaceID, report); err != nil {
+				return report, err
+			}
+			chunk = chunk[:0]
+		}
+	}
+
+	if err := s.flush(chunk, workspaceID, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// flush inserts a chunk of valid rows, skipping codes that already exist
+func (s *importService) flush(chunk []*importRow, workspaceID string, report *model.ImportReport) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	codes := make([]string, 0, len(chunk))
+	for _, row := range chunk {
+		codes = append(codes, row.record.Code)
+	}
+	existing, err := s.repo.FindExistingCodes(codes)
+	if err != nil {
+		return fmt.Errorf("failed to check short codes: %w", err)
+	}
+	taken := make(map[string]bool, len(existing)+len(chunk))
+	for _, code := range existing {
+		taken[code] = true
+	}
+
+	rows := make([]*importRow, 0, len(chunk))
+	shortURLs := make([]*model.ShortURL, 0, len(chunk))
+	for _, row := range chunk {
+		if taken[row.record.Code] {
+			report.Conflicts++
+			s.addError(report, row.line, row.record.Code, "code already exists")
+			continue
+		}
+		// Earlier chunks are already stored, so duplicates only need checking within the chunk
+		taken[row.record.Code] = true
+
+		// Destinations are kept as exported, only the hash uses the normalized form
+		normalized, _ := s.normalizer.normalize(row.record.Destination)
+		shortURL := &model.ShortURL{
+			ShortCode:   row.record.Code,
+			OriginalURL: row.record.Destination,
+			WorkspaceID: workspaceID,
+			URLHash:     hashURL(normalized),
+			ExpiresAt:   row.record.ExpiresAt,
+		}
+		if row.record.CreatedAt != nil {
+			shortURL.CreatedAt = *row.record.CreatedAt
+		}
+		shortURL.QuarantinedAt, shortURL.QuarantineReason = scanDestination(s.scanner, normalized)
+		rows = append(rows, row)
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	if len(shortURLs) == 0 {
+		return nil
+	}
+	err = s.repo.CreateBatch(shortURLs)
+	if errors.Is(err, repository.ErrDuplicateShortCode) {
+		// A code was taken after the check, insert row by row to find it
+		return s.insertEach(rows, shortURLs, report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import short URLs: %w", err)
+	}
+	for _, shortURL := range shortURLs {
+		s.countImported(shortURL, report)
+	}
+	return nil
+}
+
+// insertEach inserts the short URLs one at a time, reporting codes that are
+// already taken as conflicts
+func (s *importService) insertEach(rows []*importRow, shortURLs []*model.ShortURL, report *model.ImportReport) error {
+	for i, shortURL := range shortURLs {
+		err := s.repo.Create(shortURL)
+		if errors.Is(err, repository.ErrDuplicateShortCode) {
+			report.Conflicts++
+			s.addError(report, rows[i].line, rows[i].record.Code, "code already exists")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import short URLs: %w", err)
+		}
+		s.countImported(shortURL, report)
+	}
+	return nil
+}
+
+func (s *importService) countImported(shortURL *model.ShortURL, report *model.ImportReport) {
+	report.Imported++
+	if shortURL.QuarantinedAt != nil {
+		report.Quarantined++
+	}
+}
+
+// validateRecord returns the reason a record can't be imported, or an empty string.
+// Legacy codes only need to be URL safe, the alias length limits don't apply.
+func (s *importService) validateRecord(record *model.ImportRecord) string {
+	if record.Code == "" || len(record.Code) > maxImportCodeLength || !aliasPattern.MatchString(record.Code) {
+		return "invalid code"
+	}
+	if isReserved(record.Code, reservedAliases) || isReserved(record.Code, s.config.App.ReservedAliases) {
+		return "code is reserved"
+	}
+	if err := validateURL(record.Destination); err != nil {
+		return "invalid destination"
+	}
+	normalized, err := s.normalizer.normalize(record.Destination)
+	if err != nil {
+		return "invalid destination"
+	}
+	if err := s.policy.Check(normalized); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (s *importService) addError(report *model.ImportReport, line int, code, reason string) {
+	if len(report.Errors) >= maxImportErrors {
+		report.ErrorsTruncated = true
+		return
+	}
+	report.Errors = append(report.Errors, model.ImportRowError{Line: line, Code: code, Reason: reason})
+}
+
+// csvImportReader reads CSV with a header row naming the columns
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["code"]; !ok {
+		return nil, fmt.Errorf("invalid header")
+	}
+	if _, ok := columns["destination"]; !ok {
+		return nil, fmt.Errorf("invalid header")
+	}
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvImportReader) next() (*importRow, error) {
+	fields, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &importRowError{line: parseErr.StartLine, reason: "malformed row"}
+		}
+		return nil, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	row := &importRow{line: line}
+	row.record.Code = r.field(fields, "code")
+	row.record.Destination = r.field(fields, "destination")
+	if row.record.ExpiresAt, err = parseImportTime(r.field(fields, "expires_at")); err != nil {
+		return nil, &importRowError{line: line, reason: "invalid expires_at"}
+	}
+	if row.record.CreatedAt, err = parseImportTime(r.field(fields, "created_at")); err != nil {
+		return nil, &importRowError{line: line, reason: "invalid created_at"}
+	}
+	return row, nil
+}
+
+func (r *csvImportReader) field(fields []string, name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[i])
+}
+
+// parseImportTime accepts RFC3339 timestamps; an empty value means no time
+func parseImportTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ndjsonImportReader reads one JSON object per line
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONImportReader(r io.Reader) *ndjsonImportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+	return &ndjsonImportReader{scanner: scanner}
+}
+
+func (r *ndjsonImportReader) next() (*importRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := strings.TrimSpace(r.scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		row := &importRow{line: r.line}
+		if err := json.Unmarshal([]byte(data), &row.record); err != nil {
+			return nil, &importRowError{line: r.line, reason: "malformed row"}
+		}
+		row.record.Code = strings.TrimSpace(row.record.Code)
+		row.record.Destination = strings.TrimSpace(row.record.Destination)
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
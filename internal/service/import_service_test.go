@@ -0,0 +1,143 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImport_CSV(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	service := NewImportService(mockRepo, allowAllPolicy{}, noopScanner{}, &config.Config{})
+
+	input := strings.Join([]string{
+		"code,destination,expires_at,created_at",
+		"abc,https://example.com/a,,2020-01-02T03:04:05Z",
+		"taken,https://example.com/b,,",
+		"bad code,https://example.com/c,,",
+		"xyz,not-a-url,,",
+		"api,https://example.com/d,,",
+		"abc,https://example.com/e,,",
+		"exp,https://example.com/f,2030-01-01T00:00:00Z,",
+		"ts,https://example.com/g,tomorrow,",
+	}, "\n")
+
+	// Mock expectations
+	var stored []*model.ShortURL
+	mockRepo.On("FindExistingCodes", []string{"abc", "taken", "abc", "exp"}).Return([]string{"taken"}, nil)
+	mockRepo.On("CreateBatch", mock.AnythingOfType("[]*model.ShortURL")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).([]*model.ShortURL)
+	}).Return(nil)
+
+	// Execute
+	report, err := service.Import(strings.NewReader(input), model.FormatCSV, "growth")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 8, report.TotalRows)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 2, report.Conflicts)
+	assert.Equal(t, 4, report.Invalid)
+
+	reasons := make(map[int]string)
+	for _, rowErr := range report.Errors {
+		reasons[rowErr.Line] = rowErr.Reason
+	}
+	assert.Equal(t, map[int]string{
+		3: "code already exists",
+		4: "invalid code",
+		5: "invalid destination",
+		6: "code is reserved",
+		7: "code already exists",
+		9: "invalid expires_at",
+	}, reasons)
+
+	// Original codes and timestamps are kept
+	if assert.Len(t, stored, 2) {
+		assert.Equal(t, "abc", stored[0].ShortCode)
+		assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), stored[0].CreatedAt)
+		assert.Equal(t, "exp", stored[1].ShortCode)
+		assert.NotNil(t, stored[1].ExpiresAt)
+	}
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImport_NDJSONChunks(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	service := NewImportService(mockRepo, allowAllPolicy{}, noopScanner{}, &config.Config{})
+
+	var b strings.Builder
+	rows := importChunkSize + 10
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&b, `{"code":"c%d","destination":"https://example.com/%d"}`+"\n", i, i)
+	}
+	b.WriteString("\n{not json}\n")
+
+	// Mock expectations
+	mockRepo.On("FindExistingCodes", mock.AnythingOfType("[]string")).Return([]string{}, nil)
+	mockRepo.On("CreateBatch", mock.AnythingOfType("[]*model.ShortURL")).Return(nil)
+
+	// Execute
+	report, err := service.Import(strings.NewReader(b.String()), model.FormatNDJSON, "")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, rows+1, report.TotalRows)
+	assert.Equal(t, rows, report.Imported)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, []model.ImportRowError{{Line: rows + 2, Reason: "malformed row"}}, report.Errors)
+
+	// Verify mock calls
+	mockRepo.AssertNumberOfCalls(t, "CreateBatch", 2)
+}
+
+func TestImport_InvalidInput(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	service := NewImportService(mockRepo, allowAllPolicy{}, noopScanner{}, &config.Config{})
+
+	// Execute & Assert
+	_, err := service.Import(strings.NewReader(""), "xml", "")
+	assert.EqualError(t, err, "invalid format")
+
+	_, err = service.Import(strings.NewReader("slug,url\nabc,https://example.com\n"), model.FormatCSV, "")
+	assert.EqualError(t, err, "invalid header")
+
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+func TestImport_ConcurrentConflict(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	service := NewImportService(mockRepo, allowAllPolicy{}, noopScanner{}, &config.Config{})
+	input := "code,destination\nabc,https://example.com/a\nraced,https://example.com/b\n"
+
+	// Mock expectations - "raced" is inserted by someone else after the check
+	mockRepo.On("FindExistingCodes", []string{"abc", "raced"}).Return([]string{}, nil)
+	mockRepo.On("CreateBatch", mock.AnythingOfType("[]*model.ShortURL")).Return(repository.ErrDuplicateShortCode)
+	mockRepo.On("Create", mock.MatchedBy(func(s *model.ShortURL) bool { return s.ShortCode == "abc" })).Return(nil)
+	mockRepo.On("Create", mock.MatchedBy(func(s *model.ShortURL) bool { return s.ShortCode == "raced" })).Return(repository.ErrDuplicateShortCode)
+
+	// Execute
+	report, err := service.Import(strings.NewReader(input), model.FormatCSV, "")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, 1, report.Conflicts)
+	assert.Equal(t, []model.ImportRowError{{Line: 3, Code: "raced", Reason: "code already exists"}}, report.Errors)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shortener/internal/auth"
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// staticVerifier returns fixed claims per token
+type staticVerifier map[string]auth.Claims
+
+func (v staticVerifier) Verify(token string) (auth.Claims, error) {
+	claims, ok := v[token]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	// Setup
+	mockWorkspaces := new(MockWorkspaceRepository)
+	verifier := staticVerifier{
+		"member":   {"sub": "alice", "workspace": "growth", "scope": "links:read"},
+		"stranger": {"sub": "mallory", "workspace": "growth"},
+		"admin":    {"sub": "ops", "scp": []interface{}{"admin"}},
+		"anon":     {"workspace": "growth"},
+		// The admin scope alone doesn't make other subjects instance admins
+		"workspace-admin": {"sub": "carol", "workspace": "growth", "scope": "admin links:read"},
+		"foreign-admin":   {"sub": "mallory", "scope": "admin"},
+		"unscoped-admin":  {"sub": "ops", "scope": "links:read"},
+	}
+	authenticator := NewJWTAuthenticator(verifier, mockWorkspaces, &config.Config{Auth: config.AuthConfig{
+		JWTWorkspaceClaim: "workspace",
+		JWTAdminSubjects:  []string{"ops"},
+	}})
+
+	// Mock expectations
+	mockWorkspaces.On("FindMember", "growth", "alice").Return(&model.WorkspaceMember{Role: model.RoleEditor}, nil)
+	mockWorkspaces.On("FindMember", "growth", "mallory").Return(nil, gorm.ErrRecordNotFound)
+	mockWorkspaces.On("FindMember", "growth", "carol").Return(&model.WorkspaceMember{Role: model.RoleAdmin}, nil)
+
+	// Execute
+	member, memberErr := authenticator.Authenticate("member")
+	admin, adminErr := authenticator.Authenticate("admin")
+	workspaceAdmin, workspaceAdminErr := authenticator.Authenticate("workspace-admin")
+
+	// Assert
+	assert.NoError(t, memberErr)
+	assert.Equal(t, &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, Scopes: []string{"links:read"}}, member)
+	assert.NoError(t, adminErr)
+	assert.True(t, admin.Admin)
+	assert.NoError(t, workspaceAdminErr)
+	assert.False(t, workspaceAdmin.Admin)
+	assert.Equal(t, model.RoleAdmin, workspaceAdmin.Role)
+
+	for _, token := range []string{"stranger", "anon", "forged", "foreign-admin", "unscoped-admin"} {
+		_, err := authenticator.Authenticate(token)
+		assert.ErrorIs(t, err, ErrInvalidCredentials, token)
+	}
+}
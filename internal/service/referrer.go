@@ -0,0 +1,62 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+)
+
+// referrerDirect is reported for visits without a Referer header
+const referrerDirect = "direct"
+
+// referrerAliases folds link wrappers and app identifiers into the site they belong to
+var referrerAliases = map[string]string{
+	"t.co":                  "twitter.com",
+	"x.com":                 "twitter.com",
+	"lnkd.in":               "linkedin.com",
+	"app.slack.com":         "slack.com",
+	"com.slack":             "slack.com",
+	"com.google.android.gm": "mail.google.com",
+	"com.linkedin.android":  "linkedin.com",
+	"com.twitter.android":   "twitter.com",
+	"out.reddit.com":        "reddit.com",
+	"old.reddit.com":        "reddit.com",
+	"away.vk.com":           "vk.com",
+	"com.facebook.katana":   "facebook.com",
+	"lm.facebook.com":       "facebook.com",
+	"l.facebook.com":        "facebook.com",
+	"l.instagram.com":       "instagram.com",
+	"com.instagram.android": "instagram.com",
+}
+
+// referrerPrefixes are subdomains that don't distinguish traffic sources
+var referrerPrefixes = []string{"www.", "m.", "mobile."}
+
+// normalizeReferrer reduces a Referer header to the domain of the traffic source
+func normalizeReferrer(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return referrerDirect
+	}
+
+	parsed, err := url.Parse(referrer)
+	if err != nil || parsed.Hostname() == "" {
+		return unknownValue
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if alias, ok := referrerAliases[host]; ok {
+		return alias
+	}
+
+	for _, prefix := range referrerPrefixes {
+		if strings.HasPrefix(host, prefix) {
+			host = strings.TrimPrefix(host, prefix)
+			break
+		}
+	}
+	if alias, ok := referrerAliases[host]; ok {
+		return alias
+	}
+
+	return host
+}
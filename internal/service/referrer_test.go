@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeReferrer(t *testing.T) {
+	tests := map[string]string{
+		"":                                   "direct",
+		"https://www.google.com/":            "google.com",
+		"https://m.facebook.com/story.php":   "facebook.com",
+		"https://l.facebook.com/l.php?u=x":   "facebook.com",
+		"https://t.co/abc":                   "twitter.com",
+		"https://app.slack.com/client/T1/C1": "slack.com",
+		"android-app://com.slack":            "slack.com",
+		"https://mail.google.com/mail/u/0/":  "mail.google.com",
+		"https://News.Example.COM/path":      "news.example.com",
+		"not a url":                          "unknown",
+	}
+
+	for referrer, expected := range tests {
+		assert.Equal(t, expected, normalizeReferrer(referrer), referrer)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"go.uber.org/zap"
+)
+
+// rollupLateArrival is how far back each pass re-aggregates, so clicks that are
+// still buffered in the click pipeline are picked up by a later pass
+const rollupLateArrival = 10 * time.Minute
+
+// RollupAggregator periodically folds raw clicks into hourly and daily rollups
+type RollupAggregator struct {
+	rollupRepo repository.ClickRollupRepository
+	interval   time.Duration
+
+	watermark *time.Time
+	stop      chan struct{}
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewRollupAggregator(rollupRepo repository.ClickRollupRepository, cfg *config.Config) *RollupAggregator {
+	interval := time.Duration(cfg.Analytics.RollupIntervalSec) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &RollupAggregator{
+		rollupRepo: rollupRepo,
+		interval:   interval,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start runs an aggregation pass immediately and then on every interval
+func (a *RollupAggregator) Start() {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := a.RunOnce(time.Now()); err != nil {
+				logger.Error("Click rollup failed", zap.Error(err))
+			}
+
+			select {
+			case <-ticker.C:
+			case <-a.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background loop and waits for a running pass to finish. It is
+// safe to call more than once.
+func (a *RollupAggregator) Stop() {
+	a.stopOnce.Do(func() { close(a.stop) })
+	a.wg.Wait()
+}
+
+// RunOnce recomputes every bucket touched since the previous pass
+func (a *RollupAggregator) RunOnce(now time.Time) error {
+	now = now.UTC()
+
+	if a.watermark == nil {
+		// Resume from the newest hourly bucket so restarts don't rescan all clicks
+		latest, err := a.rollupRepo.LatestBucket(model.GranularityHour)
+		if err != nil {
+			return fmt.Errorf("failed to load rollup watermark: %w", err)
+		}
+		start := time.Time{}
+		if latest != nil {
+			start = *latest
+		}
+		a.watermark = &start
+	}
+
+	hourFrom := a.watermark.Add(-rollupLateArrival).UTC().Truncate(time.Hour)
+	if a.watermark.IsZero() {
+		hourFrom = time.Time{}
+	}
+	if err := a.rollupRepo.RollupHourly(hourFrom, now); err != nil {
+		return fmt.Errorf("failed to roll up hourly clicks: %w", err)
+	}
+
+	dayFrom := truncateToDay(hourFrom)
+	if err := a.rollupRepo.RollupDaily(dayFrom, now); err != nil {
+		return fmt.Errorf("failed to roll up daily clicks: %w", err)
+	}
+
+	a.watermark = &now
+	return nil
+}
+
+// truncateToDay returns midnight UTC of the given time
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
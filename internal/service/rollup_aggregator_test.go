@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollupAggregator_RunOnce(t *testing.T) {
+	// Setup
+	mockRollupRepo := new(MockClickRollupRepository)
+	aggregator := NewRollupAggregator(mockRollupRepo, &config.Config{})
+
+	// Test data
+	latest := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	firstRun := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	secondRun := time.Date(2024, 3, 1, 13, 5, 0, 0, time.UTC)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Mock expectations - first pass resumes from the newest hourly bucket,
+	// minus the late arrival window
+	mockRollupRepo.On("LatestBucket", model.GranularityHour).Return(&latest, nil).Once()
+	mockRollupRepo.On("RollupHourly", latest.Add(-time.Hour), firstRun).Return(nil).Once()
+	mockRollupRepo.On("RollupDaily", day, firstRun).Return(nil).Once()
+
+	// Second pass starts from the hour of the previous pass
+	mockRollupRepo.On("RollupHourly", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), secondRun).Return(nil).Once()
+	mockRollupRepo.On("RollupDaily", day, secondRun).Return(nil).Once()
+
+	// Execute
+	err := aggregator.RunOnce(firstRun)
+	assert.NoError(t, err)
+	err = aggregator.RunOnce(secondRun)
+	assert.NoError(t, err)
+
+	// Verify mock calls
+	mockRollupRepo.AssertExpectations(t)
+}
+
+func TestRollupAggregator_RunOnceWithoutRollups(t *testing.T) {
+	// Setup
+	mockRollupRepo := new(MockClickRollupRepository)
+	aggregator := NewRollupAggregator(mockRollupRepo, &config.Config{})
+	now := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	// Mock expectations - without rollups every click is aggregated
+	mockRollupRepo.On("LatestBucket", model.GranularityHour).Return(nil, nil)
+	mockRollupRepo.On("RollupHourly", time.Time{}, now).Return(nil)
+	mockRollupRepo.On("RollupDaily", time.Time{}, now).Return(nil)
+
+	// Execute
+	err := aggregator.RunOnce(now)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRollupRepo.AssertExpectations(t)
+}
+
+func TestRollupAggregator_StopTwice(t *testing.T) {
+	// Setup
+	mockRollupRepo := new(MockClickRollupRepository)
+	aggregator := NewRollupAggregator(mockRollupRepo, &config.Config{})
+
+	// Execute & Assert - shutdown paths may both stop the aggregator
+	aggregator.Stop()
+	assert.NotPanics(t, aggregator.Stop)
+}
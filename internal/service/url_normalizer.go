@@ -0,0 +1,126 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/shortener/internal/config"
+	"golang.org/x/net/idna"
+)
+
+// defaultPorts are dropped from normalized URLs
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// urlNormalizer rewrites equivalent spellings of a destination into one
+// canonical form before it is stored and hashed
+type urlNormalizer struct {
+	sortQuery      bool
+	trackingParams []string
+}
+
+func newURLNormalizer(cfg *config.Config) *urlNormalizer {
+	n := &urlNormalizer{sortQuery: cfg.URLs.SortQueryParams}
+	if cfg.URLs.StripTrackingParams {
+		for _, param := range cfg.URLs.TrackingParams {
+			n.trackingParams = append(n.trackingParams, strings.ToLower(param))
+		}
+	}
+	return n
+}
+
+// normalize lowercases scheme and host, converts IDN hosts to punycode, drops
+// default ports, strips tracking parameters and optionally sorts the query.
+// URLs without a host, such as mailto: links, are only trimmed.
+func (n *urlNormalizer) normalize(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url")
+	}
+	if parsed.Host == "" {
+		return rawURL, nil
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if strings.Contains(host, ":") {
+		// Keep IPv6 literals bracketed
+		host = "[" + host + "]"
+	} else if host, err = idna.Lookup.ToASCII(host); err != nil {
+		return "", fmt.Errorf("invalid url")
+	}
+	if port := parsed.Port(); port != "" && port != defaultPorts[parsed.Scheme] {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+	parsed.Host = host
+
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	parsed.RawQuery = n.normalizeQuery(parsed.RawQuery)
+	parsed.ForceQuery = false
+
+	return parsed.String(), nil
+}
+
+// normalizeQuery works on the raw pairs so the original encoding and order of
+// the remaining parameters are kept
+func (n *urlNormalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var pairs []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key := pair
+		if i := strings.IndexByte(pair, '='); i >= 0 {
+			key = pair[:i]
+		}
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if n.isTrackingParam(key) {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if n.sortQuery {
+		sort.Strings(pairs)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// isTrackingParam matches a parameter against the configured names, a
+// trailing * matches any suffix
+func (n *urlNormalizer) isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	for _, param := range n.trackingParams {
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == param {
+			return true
+		}
+	}
+	return false
+}
+
+// hashURL returns the hex SHA-256 of a normalized destination
+func hashURL(normalizedURL string) string {
+	sum := sha256.Sum256([]byte(normalizedURL))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shortener/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLNormalizer(t *testing.T) {
+	normalizer := newURLNormalizer(&config.Config{
+		URLs: config.URLConfig{
+			StripTrackingParams: true,
+			TrackingParams:      []string{"utm_*", "fbclid", "gclid"},
+		},
+	})
+
+	tests := map[string]string{
+		"https://Example.COM":                                 "https://example.com/",
+		"HTTPS://example.com:443/Path?q=1":                    "https://example.com/Path?q=1",
+		"http://example.com:80/a":                             "http://example.com/a",
+		"http://example.com:8080/a":                           "http://example.com:8080/a",
+		"  https://example.com/a#frag  ":                      "https://example.com/a#frag",
+		"http://[2001:DB8::1]:80/":                            "http://[2001:db8::1]/",
+		"http://[2001:db8::1]:8080/":                          "http://[2001:db8::1]:8080/",
+		"https://Bücher.example/kitap":                        "https://xn--bcher-kva.example/kitap",
+		"https://example.com/a?b=2&utm_source=x&a=1&fbclid=y": "https://example.com/a?b=2&a=1",
+		"https://example.com/a?UTM_Medium=x&gclid=1":          "https://example.com/a",
+		"https://example.com/a?q=caf%C3%A9&utm_campaign=z":    "https://example.com/a?q=caf%C3%A9",
+		"mailto:someone@example.com":                          "mailto:someone@example.com",
+	}
+
+	for rawURL, expected := range tests {
+		normalized, err := normalizer.normalize(rawURL)
+		assert.NoError(t, err, rawURL)
+		assert.Equal(t, expected, normalized, rawURL)
+	}
+}
+
+func TestURLNormalizer_Options(t *testing.T) {
+	// Sorting enabled, stripping disabled
+	normalizer := newURLNormalizer(&config.Config{
+		URLs: config.URLConfig{
+			SortQueryParams: true,
+			TrackingParams:  []string{"utm_*"},
+		},
+	})
+
+	normalized, err := normalizer.normalize("https://example.com/?b=2&utm_source=x&a=1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/?a=1&b=2&utm_source=x", normalized)
+
+	// Hosts that are not valid IDNA are rejected
+	_, err = normalizer.normalize("https://exa_mple..com/")
+	assert.EqualError(t, err, "invalid url")
+
+	// Equivalent spellings share a hash
+	a, _ := normalizer.normalize("https://Example.com")
+	b, _ := normalizer.normalize("https://example.com:443/")
+	assert.Equal(t, hashURL(a), hashURL(b))
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// ErrURLQuarantined is returned when resolving a link that was flagged as malicious
+var ErrURLQuarantined = errors.New("short URL is quarantined")
+
+const (
+	ScannerNone  = "none"
+	ScannerLocal = "local"
+	ScannerHTTP  = "http"
+)
+
+// ScanResult is the verdict of a URL scanner for one destination
+type ScanResult struct {
+	Flagged bool
+	Reason  string
+}
+
+// URLScanner checks destinations against a source of known malicious URLs
+type URLScanner interface {
+	Scan(destination string) (*ScanResult, error)
+}
+
+// NewURLScanner builds the scanner selected by URL_SCANNER
+func NewURLScanner(cfg *config.Config) (URLScanner, error) {
+	switch cfg.URLs.Scanner {
+	case "", ScannerNone:
+		return noopScanner{}, nil
+	case ScannerLocal:
+		return NewHashPrefixScanner(cfg)
+	case ScannerHTTP:
+		return NewHTTPScanner(cfg)
+	default:
+		return nil, fmt.Errorf("unknown url scanner %q", cfg.URLs.Scanner)
+	}
+}
+
+// noopScanner flags nothing, used when scanning is disabled
+type noopScanner struct{}
+
+func (noopScanner) Scan(string) (*ScanResult, error) {
+	return &ScanResult{}, nil
+}
+
+// scanDestination returns the quarantine time and reason for a flagged destination.
+// A failing scanner is logged and the destination let through, so an unavailable
+// scanner does not stop links from being created.
+func scanDestination(scanner URLScanner, destination string) (*time.Time, string) {
+	result, err := scanner.Scan(destination)
+	if err != nil {
+		logger.Warn("URL scan failed, destination not checked", zap.String("url", destination), zap.Error(err))
+		return nil, ""
+	}
+	if !result.Flagged {
+		return nil, ""
+	}
+	now := time.Now()
+	return &now, result.Reason
+}
+
+// hashPrefixes is one loaded version of the blocklist, keyed by the raw prefix bytes
+type hashPrefixes struct {
+	prefixes map[string]bool
+	lengths  []int
+}
+
+// HashPrefixScanner matches destinations against a local file of SHA-256 hash
+// prefixes in the style of Safe Browsing: every host suffix and path prefix
+// combination of the URL is hashed and looked up. The file is checked for
+// changes at most once per reload interval, on the next scan.
+type HashPrefixScanner struct {
+	file      listFile
+	interval  time.Duration
+	prefixes  atomic.Pointer[hashPrefixes]
+	mu        sync.Mutex
+	checkedAt time.Time
+}
+
+func NewHashPrefixScanner(cfg *config.Config) (*HashPrefixScanner, error) {
+	if cfg.URLs.ScannerBlocklistFile == "" {
+		return nil, fmt.Errorf("URL_SCANNER_BLOCKLIST_FILE is required for the local scanner")
+	}
+	interval := time.Duration(cfg.URLs.PolicyReloadSec) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	s := &HashPrefixScanner{
+		file:     listFile{path: cfg.URLs.ScannerBlocklistFile},
+		interval: interval,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.checkedAt = time.Now()
+	return s, nil
+}
+
+func (s *HashPrefixScanner) Scan(destination string) (*ScanResult, error) {
+	s.maybeReload()
+
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url")
+	}
+
+	list := s.prefixes.Load()
+	for _, expression := range urlExpressions(parsed) {
+		sum := sha256.Sum256([]byte(expression))
+		for _, n := range list.lengths {
+			if list.prefixes[string(sum[:n])] {
+				return &ScanResult{Flagged: true, Reason: "matched local blocklist: " + expression}, nil
+			}
+		}
+	}
+	return &ScanResult{}, nil
+}
+
+// maybeReload reloads the blocklist when the interval passed and the file changed.
+// Only one caller checks at a time, the others keep scanning with the loaded list.
+func (s *HashPrefixScanner) maybeReload() {
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	if time.Since(s.checkedAt) < s.interval {
+		return
+	}
+	s.checkedAt = time.Now()
+
+	changed, err := s.file.changed()
+	if err == nil && changed {
+		err = s.load()
+		if err == nil {
+			logger.Info("URL blocklist reloaded", zap.Int("prefixes", len(s.prefixes.Load().prefixes)))
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to reload URL blocklist, keeping previous list", zap.Error(err))
+	}
+}
+
+func (s *HashPrefixScanner) load() error {
+	list, info, err := readHashPrefixes(s.file.path)
+	if err != nil {
+		return err
+	}
+	s.file.update(info)
+	s.prefixes.Store(list)
+	return nil
+}
+
+// readHashPrefixes parses one hex encoded hash prefix of 4 to 32 bytes per line;
+// blank lines and # comments are skipped
+func readHashPrefixes(path string) (*hashPrefixes, os.FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	list := &hashPrefixes{prefixes: make(map[string]bool)}
+	seenLength := make(map[int]bool)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := scanner.Text()
+		if i := strings.IndexByte(entry, '#'); i >= 0 {
+			entry = entry[:i]
+		}
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := hex.DecodeString(entry)
+		if err != nil || len(prefix) < 4 || len(prefix) > sha256.Size {
+			return nil, nil, fmt.Errorf("%s:%d: invalid hash prefix %q", path, line, entry)
+		}
+		list.prefixes[string(prefix)] = true
+		if !seenLength[len(prefix)] {
+			seenLength[len(prefix)] = true
+			list.lengths = append(list.lengths, len(prefix))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return list, info, nil
+}
+
+// urlExpressions returns the host suffix and path prefix combinations that are
+// hashed for a lookup. For http://a.b.example.com/1/2.html?q=1 these are the
+// exact host and up to four suffixes (b.example.com, example.com) combined with
+// the full path and query, the path, / and up to three directory prefixes (/1/).
+func urlExpressions(u *url.URL) []string {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	hosts := []string{host}
+	if net.ParseIP(host) == nil {
+		components := strings.Split(host, ".")
+		start := len(components) - 5
+		if start < 1 {
+			start = 1
+		}
+		for i := start; i < len(components)-1; i++ {
+			hosts = append(hosts, strings.Join(components[i:], "."))
+		}
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var paths []string
+	if u.RawQuery != "" {
+		paths = append(paths, path+"?"+u.RawQuery)
+	}
+	paths = append(paths, path)
+	prefix := "/"
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := 0; i < len(segments) && i < 4; i++ {
+		if prefix != path {
+			paths = append(paths, prefix)
+		}
+		if i == len(segments)-1 {
+			break
+		}
+		prefix += segments[i] + "/"
+	}
+
+	expressions := make([]string, 0, len(hosts)*len(paths))
+	for _, h := range hosts {
+		for _, p := range paths {
+			expressions = append(expressions, h+p)
+		}
+	}
+	return expressions
+}
+
+// HTTPScanner asks an internal scanning service about each destination. It posts
+// {"url": "..."} and expects {"flagged": bool, "reason": "..."} with a 2xx status.
+type HTTPScanner struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+func NewHTTPScanner(cfg *config.Config) (*HTTPScanner, error) {
+	if cfg.URLs.ScannerEndpoint == "" {
+		return nil, fmt.Errorf("URL_SCANNER_ENDPOINT is required for the http scanner")
+	}
+	timeout := time.Duration(cfg.URLs.ScannerTimeoutMS) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	return &HTTPScanner{
+		endpoint: cfg.URLs.ScannerEndpoint,
+		token:    cfg.URLs.ScannerToken,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type scanRequest struct {
+	URL string `json:"url"`
+}
+
+type scanResponse struct {
+	Flagged bool   `json:"flagged"`
+	Reason  string `json:"reason"`
+}
+
+func (s *HTTPScanner) Scan(destination string) (*ScanResult, error) {
+	body, err := json.Marshal(scanRequest{URL: destination})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build scan request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scan request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("scanner returned status %d", resp.StatusCode)
+	}
+
+	var verdict scanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("invalid scanner response: %w", err)
+	}
+	return &ScanResult{Flagged: verdict.Flagged, Reason: verdict.Reason}, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// flagAllScanner flags every destination
+type flagAllScanner struct{}
+
+func (flagAllScanner) Scan(destination string) (*ScanResult, error) {
+	return &ScanResult{Flagged: true, Reason: "test"}, nil
+}
+
+func hashPrefix(expression string, n int) string {
+	sum := sha256.Sum256([]byte(expression))
+	return hex.EncodeToString(sum[:n])
+}
+
+func TestURLExpressions(t *testing.T) {
+	// Setup
+	parsed, err := url.Parse("http://a.b.example.com/1/2.html?param=1")
+	assert.NoError(t, err)
+
+	// Execute
+	expressions := urlExpressions(parsed)
+
+	// Assert
+	assert.ElementsMatch(t, []string{
+		"a.b.example.com/1/2.html?param=1", "a.b.example.com/1/2.html", "a.b.example.com/", "a.b.example.com/1/",
+		"b.example.com/1/2.html?param=1", "b.example.com/1/2.html", "b.example.com/", "b.example.com/1/",
+		"example.com/1/2.html?param=1", "example.com/1/2.html", "example.com/", "example.com/1/",
+	}, expressions)
+}
+
+func TestHashPrefixScanner_Scan(t *testing.T) {
+	// Setup
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	content := "# phishing kit\n" +
+		hashPrefix("evil.example/", 4) + "\n" +
+		hashPrefix("example.com/login/", 32) + "  # full hash\n"
+	assert.NoError(t, os.WriteFile(blocklist, []byte(content), 0o644))
+
+	cfg := &config.Config{URLs: config.URLConfig{Scanner: ScannerLocal, ScannerBlocklistFile: blocklist}}
+	scanner, err := NewURLScanner(cfg)
+	assert.NoError(t, err)
+
+	flagged := []string{
+		"https://evil.example/",
+		"https://cdn.evil.example/any/path?x=1",
+		"https://www.example.com/login/index.html",
+	}
+	clean := []string{
+		"https://example.com/",
+		"https://example.com/logout/",
+		"https://notevil.example.org/",
+	}
+
+	// Execute & Assert
+	for _, destination := range flagged {
+		result, err := scanner.Scan(destination)
+		assert.NoError(t, err)
+		assert.True(t, result.Flagged, destination)
+	}
+	for _, destination := range clean {
+		result, err := scanner.Scan(destination)
+		assert.NoError(t, err)
+		assert.False(t, result.Flagged, destination)
+	}
+}
+
+func TestHashPrefixScanner_InvalidFile(t *testing.T) {
+	// Setup
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	assert.NoError(t, os.WriteFile(blocklist, []byte("abc\n"), 0o644))
+	cfg := &config.Config{URLs: config.URLConfig{ScannerBlocklistFile: blocklist}}
+
+	// Execute
+	_, err := NewHashPrefixScanner(cfg)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestHTTPScanner_Scan(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer scan-token", r.Header.Get("Authorization"))
+		var req scanRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.URL == "https://broken.example/" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(scanResponse{Flagged: req.URL == "https://malware.example/", Reason: "malware"})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{URLs: config.URLConfig{
+		Scanner:         ScannerHTTP,
+		ScannerEndpoint: server.URL,
+		ScannerToken:    "scan-token",
+	}}
+	scanner, err := NewURLScanner(cfg)
+	assert.NoError(t, err)
+
+	// Execute
+	flagged, flaggedErr := scanner.Scan("https://malware.example/")
+	clean, cleanErr := scanner.Scan("https://example.com/")
+	_, brokenErr := scanner.Scan("https://broken.example/")
+
+	// Assert
+	assert.NoError(t, flaggedErr)
+	assert.Equal(t, &ScanResult{Flagged: true, Reason: "malware"}, flagged)
+	assert.NoError(t, cleanErr)
+	assert.False(t, clean.Flagged)
+	assert.Error(t, brokenErr)
+}
+
+func TestCreateShortURL_Quarantined(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "http://localhost:8080", CacheTTL: 3600}}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: 6}, allowAllPolicy{}, flagAllScanner{}, cfg)
+
+	// Mock expectations
+	mockRepo.On("Create", mock.MatchedBy(func(shortURL *model.ShortURL) bool {
+		return shortURL.QuarantinedAt != nil && shortURL.QuarantineReason == "test"
+	})).Return(nil)
+
+	// Execute
+	response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "https://malware.example/"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, response.Quarantined)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetOriginalURL_ScanOnRedirect(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App:  config.AppConfig{BaseURL: "http://localhost:8080", CacheTTL: 3600},
+		URLs: config.URLConfig{ScanOnRedirect: true},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: 6}, allowAllPolicy{}, flagAllScanner{}, cfg)
+
+	// Mock expectations
+	mockCache.On("Get", "short_url:abc123").Return("", assert.AnError)
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123", OriginalURL: "https://malware.example/"}, nil)
+	mockRepo.On("Quarantine", "abc123", "test").Return(nil)
+
+	// Execute
+	_, err := service.GetOriginalURL("abc123")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrURLQuarantined)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateShortURL_Quarantined(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "http://localhost:8080", CacheTTL: 3600}}
+	newURL := "https://malware.example/"
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: 6}, allowAllPolicy{}, flagAllScanner{}, cfg)
+
+	// Mock expectations - the quarantine is set on its own so a concurrent
+	// moderator decision is not overwritten with the loaded row
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123", OriginalURL: "https://example.com/"}, nil)
+	mockRepo.On("Update", mock.Anything).Return(nil)
+	mockRepo.On("Quarantine", "abc123", "test").Return(nil)
+	mockCache.On("Delete", "short_url:abc123").Return(nil)
+
+	// Execute
+	response, err := service.UpdateShortURL(testAdmin, "abc123", &model.UpdateShortURLRequest{URL: &newURL})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, response.Quarantined)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
-	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -14,60 +16,122 @@ import (
 	"gorm.io/gorm"
 )
 
+// aliasPattern restricts custom aliases to URL safe characters
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedAliases collide with application routes and can never be used as aliases
+var reservedAliases = []string{"api", "healthz", "swagger"}
+
+var (
+	// ErrAliasInUse is returned when a custom alias is already taken
+	ErrAliasInUse = errors.New("alias already in use")
+	// ErrCodeCollision is returned when every generated code was taken by the time it was inserted
+	ErrCodeCollision = errors.New("failed to find a free short code")
+)
+
+// maxCodeRetries is how often an insert is retried with a fresh code after a collision
+const maxCodeRetries = 5
+
+// URLService manages short URLs. Links belong to the workspace of the principal
+// that created them and are invisible to other workspaces. Which members may
+// read or change them is decided by their role, see RoleMiddleware.
 type URLService interface {
-	CreateShortURL(req *model.CreateShortURLRequest) (*model.CreateShortURLResponse, error)
+	CreateShortURL(principal *model.Principal, req *model.CreateShortURLRequest) (*model.CreateShortURLResponse, error)
+	CreateShortURLs(principal *model.Principal, items []model.CreateShortURLRequest) (*model.BatchCreateShortURLResponse, error)
 	GetOriginalURL(shortCode string) (string, error)
-	GetURLStats(shortCode string) (*model.URLStatsResponse, error)
+	GetURLStats(principal *model.Principal, shortCode string) (*model.URLStatsResponse, error)
+	AuthorizeShortURL(principal *model.Principal, shortCode string) error
+	UpdateShortURL(principal *model.Principal, shortCode string, req *model.UpdateShortURLRequest) (*model.CreateShortURLResponse, error)
+	DeleteShortURL(principal *model.Principal, shortCode string) error
+	ListShortURLs(principal *model.Principal, req *model.ListShortURLsRequest) (*model.ListShortURLsResponse, error)
+	GetCodeGeneratorMetrics() model.CodeGeneratorMetrics
 }
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
 type urlService struct {
-	repo   repository.ShortURLRepository
-	cache  cache.CacheInterface
-	config *config.Config
+	repo       repository.ShortURLRepository
+	cache      cache.CacheInterface
+	codes      CodeGenerator
+	normalizer *urlNormalizer
+	policy     DestinationPolicy
+	scanner    URLScanner
+	config     *config.Config
 }
 
-func NewURLService(repo repository.ShortURLRepository, cache cache.CacheInterface, cfg *config.Config) URLService {
+func NewURLService(repo repository.ShortURLRepository, cache cache.CacheInterface, codes CodeGenerator, policy DestinationPolicy, scanner URLScanner, cfg *config.Config) URLService {
 	return &urlService{
-		repo:   repo,
-		cache:  cache,
-		config: cfg,
+		repo:       repo,
+		cache:      cache,
+		codes:      codes,
+		normalizer: newURLNormalizer(cfg),
+		policy:     policy,
+		scanner:    scanner,
+		config:     cfg,
 	}
 }
 
-func (s *urlService) CreateShortURL(req *model.CreateShortURLRequest) (*model.CreateShortURLResponse, error) {
-	shortCode, err := s.generateShortCode()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate short code: %w", err)
+func (s *urlService) CreateShortURL(principal *model.Principal, req *model.CreateShortURLRequest) (*model.CreateShortURLResponse, error) {
+	if req.Alias != "" {
+		if err := s.validateAlias(req.Alias); err != nil {
+			return nil, err
+		}
 	}
 
-	shortURL := &model.ShortURL{
-		ShortCode:   shortCode,
-		OriginalURL: req.URL,
-		ExpiresAt:   req.ExpiresAt,
+	destination, err := s.prepareDestination(req.URL)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(shortURL); err != nil {
-		return nil, fmt.Errorf("failed to create short URL: %w", err)
+	urlHash := hashURL(destination)
+	if req.Alias == "" && s.shouldDeduplicate(req) {
+		// Best effort: two concurrent requests for the same destination can still both create a link.
+		// Only links of the caller's workspace are reused, another team's link is never handed out.
+		existing, err := s.repo.FindActiveByHash(principal.WorkspaceID, urlHash, req.ExpiresAt)
+		if err == nil {
+			return &model.CreateShortURLResponse{
+				ShortCode:    existing.ShortCode,
+				ShortURL:     fmt.Sprintf("%s/%s", s.config.App.BaseURL, existing.ShortCode),
+				OriginalURL:  existing.OriginalURL,
+				ExpiresAt:    existing.ExpiresAt,
+				Deduplicated: true,
+				Quarantined:  existing.QuarantinedAt != nil,
+			}, nil
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to check for duplicate URL: %w", err)
+		}
 	}
 
-	// Cache the URL
-	cacheKey := fmt.Sprintf("short_url:%s", shortCode)
-	expiration := time.Duration(s.config.App.CacheTTL) * time.Second
-
-	if req.ExpiresAt != nil {
-		timeUntilExpiry := time.Until(*req.ExpiresAt)
-		if timeUntilExpiry < expiration {
-			expiration = timeUntilExpiry
-		}
+	// Flagged links are stored quarantined so security can review them
+	quarantinedAt, reason := scanDestination(s.scanner, destination)
+	shortURL := &model.ShortURL{
+		OriginalURL:      destination,
+		WorkspaceID:      principal.WorkspaceID,
+		URLHash:          urlHash,
+		ExpiresAt:        req.ExpiresAt,
+		QuarantinedAt:    quarantinedAt,
+		QuarantineReason: reason,
 	}
+	if err := s.insertShortURL(shortURL, req.Alias); err != nil {
+		return nil, err
+	}
+	shortCode := shortURL.ShortCode
 
-	s.cache.Set(cacheKey, req.URL, expiration)
+	// Cache the URL, quarantined links always go to the database
+	if quarantinedAt == nil {
+		cacheKey := fmt.Sprintf("short_url:%s", shortCode)
+		s.cache.Set(cacheKey, destination, s.cacheExpiration(req.ExpiresAt))
+	}
 
 	response := &model.CreateShortURLResponse{
 		ShortCode:   shortCode,
 		ShortURL:    fmt.Sprintf("%s/%s", s.config.App.BaseURL, shortCode),
-		OriginalURL: req.URL,
+		OriginalURL: destination,
 		ExpiresAt:   req.ExpiresAt,
+		Quarantined: quarantinedAt != nil,
 	}
 
 	return response, nil
@@ -94,26 +158,30 @@ func (s *urlService) GetOriginalURL(shortCode string) (string, error) {
 		return "", fmt.Errorf("short URL has expired")
 	}
 
-	// Cache the result
-	expiration := time.Duration(s.config.App.CacheTTL) * time.Second
-	if shortURL.ExpiresAt != nil {
-		timeUntilExpiry := time.Until(*shortURL.ExpiresAt)
-		if timeUntilExpiry < expiration {
-			expiration = timeUntilExpiry
+	if shortURL.QuarantinedAt != nil {
+		return "", ErrURLQuarantined
+	}
+
+	// Scanning on a cache miss checks each link at most once per cache TTL
+	if s.config.URLs.ScanOnRedirect {
+		if quarantinedAt, reason := scanDestination(s.scanner, shortURL.OriginalURL); quarantinedAt != nil {
+			if err := s.repo.Quarantine(shortCode, reason); err != nil {
+				return "", fmt.Errorf("failed to quarantine short URL: %w", err)
+			}
+			return "", ErrURLQuarantined
 		}
 	}
-	s.cache.Set(cacheKey, shortURL.OriginalURL, expiration)
+
+	// Cache the result
+	s.cache.Set(cacheKey, shortURL.OriginalURL, s.cacheExpiration(shortURL.ExpiresAt))
 
 	return shortURL.OriginalURL, nil
 }
 
-func (s *urlService) GetURLStats(shortCode string) (*model.URLStatsResponse, error) {
-	shortURL, err := s.repo.FindByCode(shortCode)
+func (s *urlService) GetURLStats(principal *model.Principal, shortCode string) (*model.URLStatsResponse, error) {
+	shortURL, err := s.findOwnedShortURL(principal, shortCode)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("short URL not found")
-		}
-		return nil, fmt.Errorf("failed to find short URL: %w", err)
+		return nil, err
 	}
 
 	response := &model.URLStatsResponse{
@@ -126,34 +194,296 @@ func (s *urlService) GetURLStats(shortCode string) (*model.URLStatsResponse, err
 	return response, nil
 }
 
-func (s *urlService) generateShortCode() (string, error) {
-	const maxRetries = 5
+// AuthorizeShortURL checks that the short URL exists and the principal may read
+// it, without loading anything else
+func (s *urlService) AuthorizeShortURL(principal *model.Principal, shortCode string) error {
+	_, err := s.findOwnedShortURL(principal, shortCode)
+	return err
+}
+
+func (s *urlService) UpdateShortURL(principal *model.Principal, shortCode string, req *model.UpdateShortURLRequest) (*model.CreateShortURLResponse, error) {
+	if req.URL == nil && req.ExpiresAt == nil && !req.RemoveExpiry {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	shortURL, err := s.findOwnedShortURL(principal, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	quarantine := false
+	if req.URL != nil {
+		destination, err := s.prepareDestination(*req.URL)
+		if err != nil {
+			return nil, err
+		}
+		shortURL.OriginalURL = destination
+		shortURL.URLHash = hashURL(destination)
+		// A clean destination doesn't lift an existing quarantine, that needs a review
+		if quarantinedAt, reason := scanDestination(s.scanner, destination); quarantinedAt != nil && shortURL.QuarantinedAt == nil {
+			shortURL.QuarantinedAt = quarantinedAt
+			shortURL.QuarantineReason = reason
+			quarantine = true
+		}
+	}
+	if req.RemoveExpiry {
+		shortURL.ExpiresAt = nil
+	} else if req.ExpiresAt != nil {
+		shortURL.ExpiresAt = req.ExpiresAt
+	}
 
-	for i := 0; i < maxRetries; i++ {
-		// Generate random bytes
-		randomBytes := make([]byte, s.config.App.ShortCodeLength)
-		if _, err := rand.Read(randomBytes); err != nil {
-			return "", err
+	if err := s.repo.Update(shortURL); err != nil {
+		return nil, fmt.Errorf("failed to update short URL: %w", err)
+	}
+	if quarantine {
+		if err := s.repo.Quarantine(shortCode, shortURL.QuarantineReason); err != nil {
+			return nil, fmt.Errorf("failed to quarantine short URL: %w", err)
 		}
+	}
+
+	// Evict the cached destination, the next redirect reloads it
+	if err := s.cache.Delete(fmt.Sprintf("short_url:%s", shortCode)); err != nil {
+		return nil, fmt.Errorf("failed to evict cached URL: %w", err)
+	}
+
+	response := &model.CreateShortURLResponse{
+		ShortCode:   shortURL.ShortCode,
+		ShortURL:    fmt.Sprintf("%s/%s", s.config.App.BaseURL, shortURL.ShortCode),
+		OriginalURL: shortURL.OriginalURL,
+		ExpiresAt:   shortURL.ExpiresAt,
+		Quarantined: shortURL.QuarantinedAt != nil,
+	}
+
+	return response, nil
+}
+
+func (s *urlService) DeleteShortURL(principal *model.Principal, shortCode string) error {
+	if _, err := s.findOwnedShortURL(principal, shortCode); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(shortCode); err != nil {
+		return fmt.Errorf("failed to delete short URL: %w", err)
+	}
+
+	if err := s.cache.Delete(fmt.Sprintf("short_url:%s", shortCode)); err != nil {
+		return fmt.Errorf("failed to evict cached URL: %w", err)
+	}
+
+	return nil
+}
+
+// ListShortURLs lists the links of the caller's workspace, or every link for admins
+func (s *urlService) ListShortURLs(principal *model.Principal, req *model.ListShortURLsRequest) (*model.ListShortURLsResponse, error) {
+	filter := &model.ShortURLFilter{
+		Domain:      req.Domain,
+		Status:      req.Status,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Sort:        req.Sort,
+		Descending:  true,
+		Limit:       req.Limit,
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = model.SortCreatedAt
+	case model.SortCreatedAt, model.SortClicks, model.SortExpiresAt:
+	default:
+		return nil, fmt.Errorf("invalid sort")
+	}
 
-		// Encode to base64 and clean up
-		encoded := base64.URLEncoding.EncodeToString(randomBytes)
-		shortCode := strings.ReplaceAll(encoded, "-", "")
-		shortCode = strings.ReplaceAll(shortCode, "_", "")
+	switch req.Order {
+	case "", "desc":
+	case "asc":
+		filter.Descending = false
+	default:
+		return nil, fmt.Errorf("invalid order")
+	}
 
-		if len(shortCode) > s.config.App.ShortCodeLength {
-			shortCode = shortCode[:s.config.App.ShortCodeLength]
+	switch filter.Status {
+	case "", model.StatusActive, model.StatusExpired, model.StatusQuarantined:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+
+	if !principal.Admin {
+		filter.WorkspaceID = principal.WorkspaceID
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	} else if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil || cursor.Sort != filter.Sort || cursor.Descending != filter.Descending {
+			return nil, fmt.Errorf("invalid cursor")
 		}
+		filter.After = cursor
+	}
+
+	// Fetch one extra row to know whether another page exists
+	limit := filter.Limit
+	filter.Limit++
+	shortURLs, err := s.repo.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list short URLs: %w", err)
+	}
+
+	response := &model.ListShortURLsResponse{
+		Items: make([]model.ShortURLListItem, 0, limit),
+	}
+	if len(shortURLs) > limit {
+		shortURLs = shortURLs[:limit]
+		response.NextCursor = encodeCursor(model.NewShortURLCursor(&shortURLs[limit-1], filter.Sort, filter.Descending))
+	}
+
+	for _, shortURL := range shortURLs {
+		response.Items = append(response.Items, model.ShortURLListItem{
+			ShortCode:        shortURL.ShortCode,
+			ShortURL:         fmt.Sprintf("%s/%s", s.config.App.BaseURL, shortURL.ShortCode),
+			OriginalURL:      shortURL.OriginalURL,
+			ClickCount:       shortURL.ClickCount,
+			CreatedAt:        shortURL.CreatedAt,
+			ExpiresAt:        shortURL.ExpiresAt,
+			QuarantinedAt:    shortURL.QuarantinedAt,
+			QuarantineReason: shortURL.QuarantineReason,
+		})
+	}
+
+	return response, nil
+}
 
-		// Check if already exists
-		_, err := s.repo.FindByCode(shortCode)
+// findOwnedShortURL loads a short URL of the principal's workspace. Links without
+// a workspace, created before ownership existed, are only manageable by admins.
+func (s *urlService) findOwnedShortURL(principal *model.Principal, shortCode string) (*model.ShortURL, error) {
+	shortURL, err := s.repo.FindByCode(shortCode)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return shortCode, nil
-		} else if err != nil {
-			return "", err
+			return nil, fmt.Errorf("short URL not found")
 		}
-		// If exists, retry
+		return nil, fmt.Errorf("failed to find short URL: %w", err)
+	}
+
+	if !principal.Admin && (shortURL.WorkspaceID == "" || shortURL.WorkspaceID != principal.WorkspaceID) {
+		return nil, ErrForbidden
+	}
+	return shortURL, nil
+}
+
+func (s *urlService) GetCodeGeneratorMetrics() model.CodeGeneratorMetrics {
+	return s.codes.Metrics()
+}
+
+// encodeCursor serializes a pagination cursor into an opaque URL safe token
+func encodeCursor(cursor *model.ShortURLCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string) (*model.ShortURLCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
 	}
 
-	return "", fmt.Errorf("failed to generate unique short code after %d retries", maxRetries)
+	var cursor model.ShortURLCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (s *urlService) validateAlias(alias string) error {
+	if len(alias) < s.config.App.AliasMinLength || len(alias) > s.config.App.AliasMaxLength {
+		return fmt.Errorf("invalid alias")
+	}
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf("invalid alias")
+	}
+
+	if isReserved(alias, reservedAliases) || isReserved(alias, s.config.App.ReservedAliases) {
+		return fmt.Errorf("alias is reserved")
+	}
+
+	return nil
+}
+
+func isReserved(alias string, words []string) bool {
+	for _, word := range words {
+		if strings.EqualFold(alias, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareDestination normalizes a destination and checks it against the destination policy
+func (s *urlService) prepareDestination(rawURL string) (string, error) {
+	destination, err := s.normalizer.normalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if err := s.policy.Check(destination); err != nil {
+		return "", err
+	}
+	return destination, nil
+}
+
+// shouldDeduplicate applies the per request override over the DEDUPLICATE_URLS default
+func (s *urlService) shouldDeduplicate(req *model.CreateShortURLRequest) bool {
+	if req.Deduplicate != nil {
+		return *req.Deduplicate
+	}
+	return s.config.App.DeduplicateURLs
+}
+
+// insertShortURL stores the short URL under the alias or a generated code. The
+// unique index decides who wins a code, so concurrent requests can't both get it;
+// a generated code that lost is replaced and the insert retried.
+func (s *urlService) insertShortURL(shortURL *model.ShortURL, alias string) error {
+	for attempt := 1; ; attempt++ {
+		shortURL.ShortCode = alias
+		if alias == "" {
+			code, err := s.codes.Generate()
+			if err != nil {
+				return fmt.Errorf("failed to generate short code: %w", err)
+			}
+			shortURL.ShortCode = code
+		}
+
+		err := s.repo.Create(shortURL)
+		if err != nil && !errors.Is(err, repository.ErrDuplicateShortCode) {
+			return fmt.Errorf("failed to create short URL: %w", err)
+		}
+		if alias != "" {
+			if err != nil {
+				return ErrAliasInUse
+			}
+			return nil
+		}
+
+		s.codes.Observe(err != nil)
+		if err == nil {
+			return nil
+		}
+		if attempt == maxCodeRetries {
+			return ErrCodeCollision
+		}
+	}
+}
+
+// cacheExpiration caps the cache TTL so cached URLs never outlive their expiry
+func (s *urlService) cacheExpiration(expiresAt *time.Time) time.Duration {
+	expiration := time.Duration(s.config.App.CacheTTL) * time.Second
+	if expiresAt != nil {
+		timeUntilExpiry := time.Until(*expiresAt)
+		if timeUntilExpiry < expiration {
+			expiration = timeUntilExpiry
+		}
+	}
+	return expiration
 }
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/shortener/internal/cache"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+)
+
+// CreateShortURLs validates every item on its own and stores the valid ones in a
+// single transaction. Invalid items are reported per index and don't abort the batch.
+func (s *urlService) CreateShortURLs(principal *model.Principal, items []model.CreateShortURLRequest) (*model.BatchCreateShortURLResponse, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch is empty")
+	}
+	if s.config.App.BatchMaxSize > 0 && len(items) > s.config.App.BatchMaxSize {
+		return nil, fmt.Errorf("batch too large")
+	}
+
+	// Work on a copy, destinations are replaced by their normalized form
+	items = append([]model.CreateShortURLRequest(nil), items...)
+	results := make([]model.BatchItemResult, len(items))
+	codes := make([]string, len(items))
+
+	// Validate items and collect requested aliases
+	aliasIndex := make(map[string]int)
+	for i, item := range items {
+		results[i].Index = i
+		if err := validateURL(item.URL); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		destination, err := s.prepareDestination(item.URL)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		items[i].URL = destination
+		if item.Alias == "" {
+			continue
+		}
+		if err := s.validateAlias(item.Alias); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if _, ok := aliasIndex[item.Alias]; ok {
+			results[i].Error = ErrAliasInUse.Error()
+			continue
+		}
+		aliasIndex[item.Alias] = i
+		codes[i] = item.Alias
+	}
+
+	// Scan once up front, the insert below may be retried
+	quarantines := make([]quarantine, len(items))
+	for i, item := range items {
+		if results[i].Error == "" {
+			quarantines[i].at, quarantines[i].reason = scanDestination(s.scanner, item.URL)
+		}
+	}
+
+	// The insert is the authority on uniqueness: when a concurrent request took
+	// one of the codes in the meantime, check aliases and codes again and retry
+	var shortURLs []*model.ShortURL
+	var entries []cache.Entry
+	for attempt := 1; ; attempt++ {
+		var err error
+		shortURLs, entries, err = s.prepareBatch(principal.WorkspaceID, items, results, codes, aliasIndex, quarantines)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.repo.CreateBatch(shortURLs)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repository.ErrDuplicateShortCode) {
+			return nil, fmt.Errorf("failed to create short URLs: %w", err)
+		}
+		if attempt == maxCodeRetries {
+			return nil, ErrCodeCollision
+		}
+	}
+
+	// Cache all new URLs in one pipelined round trip
+	s.cache.SetMany(entries)
+
+	response := &model.BatchCreateShortURLResponse{Items: results}
+	for i, item := range items {
+		if results[i].Error != "" {
+			results[i].Status = model.BatchStatusError
+			response.Failed++
+			continue
+		}
+		results[i].Status = model.BatchStatusCreated
+		results[i].Result = &model.CreateShortURLResponse{
+			ShortCode:   codes[i],
+			ShortURL:    fmt.Sprintf("%s/%s", s.config.App.BaseURL, codes[i]),
+			OriginalURL: item.URL,
+			ExpiresAt:   item.ExpiresAt,
+			Quarantined: quarantines[i].at != nil,
+		}
+		response.Created++
+	}
+
+	return response, nil
+}
+
+// quarantine is the scan verdict of a batch item, at is nil for clean destinations
+type quarantine struct {
+	at     *time.Time
+	reason string
+}
+
+// prepareBatch marks taken aliases as failed, generates codes for items without
+// an alias and builds the rows and cache entries of every valid item
+func (s *urlService) prepareBatch(workspaceID string, items []model.CreateShortURLRequest, results []model.BatchItemResult, codes []string, aliasIndex map[string]int, quarantines []quarantine) ([]*model.ShortURL, []cache.Entry, error) {
+	// Check all aliases with one query
+	var aliases []string
+	for i, item := range items {
+		if results[i].Error == "" && item.Alias != "" {
+			aliases = append(aliases, item.Alias)
+		}
+	}
+	taken, err := s.repo.FindExistingCodes(aliases)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check aliases: %w", err)
+	}
+	for _, code := range taken {
+		i := aliasIndex[code]
+		results[i].Error = ErrAliasInUse.Error()
+		codes[i] = ""
+	}
+
+	// Generate codes for the remaining valid items
+	var pending []int
+	for i, item := range items {
+		if results[i].Error == "" && item.Alias == "" {
+			pending = append(pending, i)
+		}
+	}
+	if err := s.generateBatchCodes(pending, codes, aliasIndex); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate short codes: %w", err)
+	}
+
+	var shortURLs []*model.ShortURL
+	var entries []cache.Entry
+	for i, item := range items {
+		if results[i].Error != "" {
+			continue
+		}
+		shortURLs = append(shortURLs, &model.ShortURL{
+			ShortCode:        codes[i],
+			OriginalURL:      item.URL,
+			WorkspaceID:      workspaceID,
+			URLHash:          hashURL(item.URL),
+			ExpiresAt:        item.ExpiresAt,
+			QuarantinedAt:    quarantines[i].at,
+			QuarantineReason: quarantines[i].reason,
+		})
+		if quarantines[i].at != nil {
+			continue
+		}
+		if expiration := s.cacheExpiration(item.ExpiresAt); expiration > 0 {
+			entries = append(entries, cache.Entry{
+				Key:        fmt.Sprintf("short_url:%s", codes[i]),
+				Value:      item.URL,
+				Expiration: expiration,
+			})
+		}
+	}
+	return shortURLs, entries, nil
+}
+
+// generateBatchCodes fills codes for the pending indexes, checking collisions
+// for the whole batch at once instead of one lookup per code
+func (s *urlService) generateBatchCodes(pending []int, codes []string, reserved map[string]int) error {
+	used := make(map[string]bool, len(reserved))
+	for alias := range reserved {
+		used[alias] = true
+	}
+
+	for attempt := 0; attempt < maxCodeRetries && len(pending) > 0; attempt++ {
+		candidates, err := s.codes.GenerateBatch(len(pending))
+		if err != nil {
+			return err
+		}
+		for n, i := range pending {
+			codes[i] = candidates[n]
+		}
+
+		existing, err := s.repo.FindExistingCodes(candidates)
+		if err != nil {
+			return err
+		}
+		taken := make(map[string]bool, len(existing))
+		for _, code := range existing {
+			taken[code] = true
+		}
+
+		// Keep unique candidates, retry the rest
+		var retry []int
+		for _, i := range pending {
+			collided := taken[codes[i]] || used[codes[i]]
+			s.codes.Observe(collided)
+			if collided {
+				retry = append(retry, i)
+				continue
+			}
+			used[codes[i]] = true
+		}
+		pending = retry
+	}
+
+	if len(pending) > 0 {
+		return ErrCodeCollision
+	}
+	return nil
+}
+
+// validateURL applies the same rules as the url binding of single creation
+func validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme == "" || (parsed.Host == "" && parsed.Opaque == "" && parsed.Path == "") {
+		return fmt.Errorf("invalid url")
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shortener/internal/config"
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryShortURLRepository enforces the unique short code index in memory
+type memoryShortURLRepository struct {
+	repository.ShortURLRepository
+
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+func newMemoryShortURLRepository() *memoryShortURLRepository {
+	return &memoryShortURLRepository{codes: make(map[string]string)}
+}
+
+func (r *memoryShortURLRepository) Create(shortURL *model.ShortURL) error {
+	return r.CreateBatch([]*model.ShortURL{shortURL})
+}
+
+func (r *memoryShortURLRepository) CreateBatch(shortURLs []*model.ShortURL) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, shortURL := range shortURLs {
+		if _, ok := r.codes[shortURL.ShortCode]; ok {
+			return repository.ErrDuplicateShortCode
+		}
+	}
+	for _, shortURL := range shortURLs {
+		r.codes[shortURL.ShortCode] = shortURL.OriginalURL
+	}
+	return nil
+}
+
+func (r *memoryShortURLRepository) FindExistingCodes(shortCodes []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var existing []string
+	for _, code := range shortCodes {
+		if _, ok := r.codes[code]; ok {
+			existing = append(existing, code)
+		}
+	}
+	return existing, nil
+}
+
+// repeatingCodeGenerator hands out every code twice, so concurrent callers collide
+type repeatingCodeGenerator struct {
+	counter atomic.Int64
+}
+
+func (g *repeatingCodeGenerator) Generate() (string, error) {
+	return fmt.Sprintf("c%d", g.counter.Add(1)/2), nil
+}
+
+func (g *repeatingCodeGenerator) GenerateBatch(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		codes[i], _ = g.Generate()
+	}
+	return codes, nil
+}
+
+func (g *repeatingCodeGenerator) Observe(collided bool) {}
+
+func (g *repeatingCodeGenerator) Metrics() model.CodeGeneratorMetrics {
+	return model.CodeGeneratorMetrics{}
+}
+
+// fixedCodeGenerator always returns the same code
+type fixedCodeGenerator struct {
+	code string
+}
+
+func (g fixedCodeGenerator) Generate() (string, error) {
+	return g.code, nil
+}
+
+func (g fixedCodeGenerator) GenerateBatch(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		codes[i] = g.code
+	}
+	return codes, nil
+}
+
+func (g fixedCodeGenerator) Observe(collided bool) {}
+
+func (g fixedCodeGenerator) Metrics() model.CodeGeneratorMetrics {
+	return model.CodeGeneratorMetrics{}
+}
+
+func newConcurrencyTestService(repo repository.ShortURLRepository, codes CodeGenerator) URLService {
+	mockCache := new(MockRedisClient)
+	mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockCache.On("SetMany", mock.Anything).Return(nil)
+
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:        "http://localhost:8080",
+			CacheTTL:       3600,
+			AliasMinLength: 3,
+			AliasMaxLength: 32,
+		},
+	}
+	return NewURLService(repo, mockCache, codes, allowAllPolicy{}, noopScanner{}, cfg)
+}
+
+func TestCreateShortURL_ConcurrentCollisions(t *testing.T) {
+	// Setup
+	repo := newMemoryShortURLRepository()
+	service := newConcurrencyTestService(repo, &repeatingCodeGenerator{})
+
+	const workers = 100
+	var wg sync.WaitGroup
+	codes := make([]string, workers)
+	errs := make([]error, workers)
+
+	// Execute
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: fmt.Sprintf("https://example.com/%d", i)})
+			errs[i] = err
+			if err == nil {
+				codes[i] = response.ShortCode
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert: every request got its own code
+	seen := make(map[string]bool, workers)
+	for i := 0; i < workers; i++ {
+		assert.NoError(t, errs[i])
+		assert.False(t, seen[codes[i]], "code %s handed out twice", codes[i])
+		seen[codes[i]] = true
+		assert.Equal(t, fmt.Sprintf("https://example.com/%d", i), repo.codes[codes[i]])
+	}
+	assert.Len(t, repo.codes, workers)
+}
+
+func TestCreateShortURL_ConcurrentAlias(t *testing.T) {
+	// Setup
+	repo := newMemoryShortURLRepository()
+	service := newConcurrencyTestService(repo, &repeatingCodeGenerator{})
+
+	const workers = 20
+	var wg sync.WaitGroup
+	var created, inUse atomic.Int32
+
+	// Execute
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "https://example.com", Alias: "launch"})
+			switch {
+			case err == nil:
+				created.Add(1)
+			case errors.Is(err, ErrAliasInUse):
+				inUse.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert: exactly one request won the alias
+	assert.Equal(t, int32(1), created.Load())
+	assert.Equal(t, int32(workers-1), inUse.Load())
+}
+
+func TestCreateShortURLs_ConcurrentCollisions(t *testing.T) {
+	// Setup
+	repo := newMemoryShortURLRepository()
+	service := newConcurrencyTestService(repo, &repeatingCodeGenerator{})
+
+	const workers, batchSize = 10, 10
+	var wg sync.WaitGroup
+
+	// Execute
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items := make([]model.CreateShortURLRequest, batchSize)
+			for i := range items {
+				items[i].URL = "https://example.com"
+			}
+			response, err := service.CreateShortURLs(testAdmin, items)
+			if assert.NoError(t, err) {
+				assert.Equal(t, batchSize, response.Created)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	assert.Len(t, repo.codes, workers*batchSize)
+}
+
+func TestCreateShortURL_CodeCollisionError(t *testing.T) {
+	// Setup
+	repo := newMemoryShortURLRepository()
+	repo.codes["taken"] = "https://example.com"
+	service := newConcurrencyTestService(repo, fixedCodeGenerator{code: "taken"})
+
+	// Execute
+	response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "https://example.com/other"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrCodeCollision)
+	assert.Nil(t, response)
+	assert.Equal(t, "https://example.com", repo.codes["taken"])
+}
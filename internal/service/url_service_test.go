@@ -4,13 +4,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shortener/internal/cache"
 	"github.com/shortener/internal/config"
 	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
+// testAdmin is the caller of tests that don't exercise workspaces
+var testAdmin = &model.Principal{Admin: true}
+
 // Mock Repository
 type MockShortURLRepository struct {
 	mock.Mock
@@ -21,6 +26,27 @@ func (m *MockShortURLRepository) Create(shortURL *model.ShortURL) error {
 	return args.Error(0)
 }
 
+func (m *MockShortURLRepository) CreateBatch(shortURLs []*model.ShortURL) error {
+	args := m.Called(shortURLs)
+	return args.Error(0)
+}
+
+func (m *MockShortURLRepository) FindExistingCodes(shortCodes []string) ([]string, error) {
+	args := m.Called(shortCodes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockShortURLRepository) NextCodeSequence(n int) ([]int64, error) {
+	args := m.Called(n)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
 func (m *MockShortURLRepository) FindByCode(shortCode string) (*model.ShortURL, error) {
 	args := m.Called(shortCode)
 	if args.Get(0) == nil {
@@ -29,6 +55,14 @@ func (m *MockShortURLRepository) FindByCode(shortCode string) (*model.ShortURL,
 	return args.Get(0).(*model.ShortURL), args.Error(1)
 }
 
+func (m *MockShortURLRepository) FindActiveByHash(workspaceID, urlHash string, expiresAt *time.Time) (*model.ShortURL, error) {
+	args := m.Called(workspaceID, urlHash, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ShortURL), args.Error(1)
+}
+
 func (m *MockShortURLRepository) FindByID(id uint) (*model.ShortURL, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -37,6 +71,29 @@ func (m *MockShortURLRepository) FindByID(id uint) (*model.ShortURL, error) {
 	return args.Get(0).(*model.ShortURL), args.Error(1)
 }
 
+func (m *MockShortURLRepository) List(filter *model.ShortURLFilter) ([]model.ShortURL, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ShortURL), args.Error(1)
+}
+
+func (m *MockShortURLRepository) Update(shortURL *model.ShortURL) error {
+	args := m.Called(shortURL)
+	return args.Error(0)
+}
+
+func (m *MockShortURLRepository) Quarantine(shortCode, reason string) error {
+	args := m.Called(shortCode, reason)
+	return args.Error(0)
+}
+
+func (m *MockShortURLRepository) ReleaseQuarantine(shortCode string) error {
+	args := m.Called(shortCode)
+	return args.Error(0)
+}
+
 func (m *MockShortURLRepository) Delete(shortCode string) error {
 	args := m.Called(shortCode)
 	return args.Error(0)
@@ -52,6 +109,11 @@ func (m *MockRedisClient) Set(key, value string, expiration time.Duration) error
 	return args.Error(0)
 }
 
+func (m *MockRedisClient) SetMany(entries []cache.Entry) error {
+	args := m.Called(entries)
+	return args.Error(0)
+}
+
 func (m *MockRedisClient) Get(key string) (string, error) {
 	args := m.Called(key)
 	return args.String(0), args.Error(1)
@@ -72,6 +134,13 @@ func (m *MockRedisClient) Close() error {
 	return args.Error(0)
 }
 
+// allowAllPolicy accepts every destination
+type allowAllPolicy struct{}
+
+func (allowAllPolicy) Check(destination string) error {
+	return nil
+}
+
 func TestCreateShortURL(t *testing.T) {
 	// Setup
 	mockRepo := new(MockShortURLRepository)
@@ -84,7 +153,7 @@ func TestCreateShortURL(t *testing.T) {
 		},
 	}
 
-	service := NewURLService(mockRepo, mockCache, cfg)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
 
 	// Test data
 	req := &model.CreateShortURLRequest{
@@ -93,17 +162,16 @@ func TestCreateShortURL(t *testing.T) {
 
 	// Mock expectations
 	mockRepo.On("Create", mock.AnythingOfType("*model.ShortURL")).Return(nil)
-	mockRepo.On("FindByCode", mock.AnythingOfType("string")).Return(nil, gorm.ErrRecordNotFound)
 	mockCache.On("Set", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
 
 	// Execute
-	response, err := service.CreateShortURL(req)
+	response, err := service.CreateShortURL(testAdmin, req)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, response)
 	assert.NotEmpty(t, response.ShortCode)
-	assert.Equal(t, req.URL, response.OriginalURL)
+	assert.Equal(t, "https://example.com/", response.OriginalURL)
 	assert.Contains(t, response.ShortURL, response.ShortCode)
 
 	// Verify mock calls
@@ -123,7 +191,7 @@ func TestCreateShortURLWithoutExpiry(t *testing.T) {
 		},
 	}
 
-	service := NewURLService(mockRepo, mockCache, cfg)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
 
 	// Test data - expires_at parametresi YOK
 	req := &model.CreateShortURLRequest{
@@ -132,11 +200,10 @@ func TestCreateShortURLWithoutExpiry(t *testing.T) {
 
 	// Mock expectations
 	mockRepo.On("Create", mock.AnythingOfType("*model.ShortURL")).Return(nil)
-	mockRepo.On("FindByCode", mock.AnythingOfType("string")).Return(nil, gorm.ErrRecordNotFound)
 	mockCache.On("Set", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
 
 	// Execute
-	response, err := service.CreateShortURL(req)
+	response, err := service.CreateShortURL(testAdmin, req)
 
 	// Assert
 	assert.NoError(t, err)
@@ -163,7 +230,7 @@ func TestGetOriginalURL_FromCache(t *testing.T) {
 		},
 	}
 
-	service := NewURLService(mockRepo, mockCache, cfg)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
 
 	// Test data
 	shortCode := "abc123"
@@ -196,7 +263,7 @@ func TestGetOriginalURL_FromDatabase(t *testing.T) {
 		},
 	}
 
-	service := NewURLService(mockRepo, mockCache, cfg)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
 
 	// Test data
 	shortCode := "abc123"
@@ -238,7 +305,7 @@ func TestGetOriginalURL_Expired(t *testing.T) {
 		},
 	}
 
-	service := NewURLService(mockRepo, mockCache, cfg)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
 
 	// Test data
 	shortCode := "abc123"
@@ -282,7 +349,7 @@ func TestGetURLStats(t *testing.T) {
 		},
 	}
 
-	service := NewURLService(mockRepo, mockCache, cfg)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
 
 	// Test data
 	shortCode := "abc123"
@@ -299,7 +366,7 @@ func TestGetURLStats(t *testing.T) {
 	mockRepo.On("FindByCode", shortCode).Return(shortURL, nil)
 
 	// Execute
-	stats, err := service.GetURLStats(shortCode)
+	stats, err := service.GetURLStats(testAdmin, shortCode)
 
 	// Assert
 	assert.NoError(t, err)
@@ -311,3 +378,606 @@ func TestGetURLStats(t *testing.T) {
 	// Verify mock calls
 	mockRepo.AssertExpectations(t)
 }
+
+func TestCreateShortURL_WithAlias(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+			AliasMinLength:  3,
+			AliasMaxLength:  32,
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Test data
+	req := &model.CreateShortURLRequest{
+		URL:   "https://example.com/spring",
+		Alias: "spring-sale",
+	}
+
+	// Mock expectations
+	mockRepo.On("Create", mock.AnythingOfType("*model.ShortURL")).Return(nil)
+	mockCache.On("Set", "short_url:spring-sale", req.URL, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	// Execute
+	response, err := service.CreateShortURL(testAdmin, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "spring-sale", response.ShortCode)
+	assert.Equal(t, "http://localhost:8080/spring-sale", response.ShortURL)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestCreateShortURL_AliasTaken(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+			AliasMinLength:  3,
+			AliasMaxLength:  32,
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Test data
+	req := &model.CreateShortURLRequest{
+		URL:   "https://example.com/spring",
+		Alias: "spring-sale",
+	}
+
+	// Mock expectations
+	mockRepo.On("Create", mock.AnythingOfType("*model.ShortURL")).Return(repository.ErrDuplicateShortCode)
+
+	// Execute
+	response, err := service.CreateShortURL(testAdmin, req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "alias already in use", err.Error())
+	assert.Nil(t, response)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateShortURL_InvalidAlias(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+			AliasMinLength:  3,
+			AliasMaxLength:  32,
+			ReservedAliases: []string{"admin"},
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	tests := map[string]string{
+		"ab":                                  "invalid alias",
+		"spring sale":                         "invalid alias",
+		"spring/sale":                         "invalid alias",
+		"this-alias-is-way-too-long-to-be-ok": "invalid alias",
+		"swagger":                             "alias is reserved",
+		"API":                                 "alias is reserved",
+		"admin":                               "alias is reserved",
+	}
+
+	for alias, expected := range tests {
+		// Execute
+		response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{
+			URL:   "https://example.com",
+			Alias: alias,
+		})
+
+		// Assert
+		assert.Error(t, err, alias)
+		assert.Equal(t, expected, err.Error(), alias)
+		assert.Nil(t, response)
+	}
+
+	// Verify no repository access for rejected aliases
+	mockRepo.AssertNotCalled(t, "FindByCode", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUpdateShortURL(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Test data
+	shortCode := "abc123"
+	expiresAt := time.Now().Add(time.Hour)
+	newURL := "https://example.com/fixed"
+	shortURL := &model.ShortURL{
+		ShortCode:   shortCode,
+		OriginalURL: "https://example.com/wrong",
+		ExpiresAt:   &expiresAt,
+	}
+
+	// Mock expectations
+	mockRepo.On("FindByCode", shortCode).Return(shortURL, nil)
+	mockRepo.On("Update", shortURL).Return(nil)
+	mockCache.On("Delete", "short_url:"+shortCode).Return(nil)
+
+	// Execute
+	response, err := service.UpdateShortURL(testAdmin, shortCode, &model.UpdateShortURLRequest{
+		URL:          &newURL,
+		RemoveExpiry: true,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, newURL, response.OriginalURL)
+	assert.Nil(t, response.ExpiresAt)
+	assert.Equal(t, newURL, shortURL.OriginalURL)
+	assert.Nil(t, shortURL.ExpiresAt)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestUpdateShortURL_NotFound(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Test data
+	newURL := "https://example.com/fixed"
+
+	// Mock expectations
+	mockRepo.On("FindByCode", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Execute
+	response, err := service.UpdateShortURL(testAdmin, "missing", &model.UpdateShortURLRequest{URL: &newURL})
+
+	// Assert
+	assert.EqualError(t, err, "short URL not found")
+	assert.Nil(t, response)
+
+	// Empty updates are rejected before touching the repository
+	_, err = service.UpdateShortURL(testAdmin, "abc123", &model.UpdateShortURLRequest{})
+	assert.EqualError(t, err, "no fields to update")
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockCache.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestDeleteShortURL(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Test data
+	shortCode := "abc123"
+
+	// Mock expectations
+	mockRepo.On("FindByCode", shortCode).Return(&model.ShortURL{ShortCode: shortCode}, nil)
+	mockRepo.On("Delete", shortCode).Return(nil)
+	mockCache.On("Delete", "short_url:"+shortCode).Return(nil)
+
+	// Execute
+	err := service.DeleteShortURL(testAdmin, shortCode)
+
+	// Assert
+	assert.NoError(t, err)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestListShortURLs_Pagination(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL: "http://localhost:8080",
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Test data
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	page := []model.ShortURL{
+		{ID: 30, ShortCode: "c", OriginalURL: "https://example.com/c", ClickCount: 9, CreatedAt: createdAt},
+		{ID: 20, ShortCode: "b", OriginalURL: "https://example.com/b", ClickCount: 5, CreatedAt: createdAt},
+		{ID: 10, ShortCode: "a", OriginalURL: "https://example.com/a", ClickCount: 1, CreatedAt: createdAt},
+	}
+
+	// Mock expectations - one extra row is requested to detect the next page
+	mockRepo.On("List", mock.MatchedBy(func(f *model.ShortURLFilter) bool {
+		return f.After == nil && f.Limit == 3 && f.Sort == model.SortClicks && f.Descending && f.Domain == "example"
+	})).Return(page, nil).Once()
+	mockRepo.On("List", mock.MatchedBy(func(f *model.ShortURLFilter) bool {
+		return f.After != nil && f.After.ID == 20 && f.After.Clicks == 5
+	})).Return(page[2:], nil).Once()
+
+	// Execute - first page
+	first, err := service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{
+		Limit:  2,
+		Sort:   model.SortClicks,
+		Domain: "example",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, first.Items, 2)
+	assert.Equal(t, "c", first.Items[0].ShortCode)
+	assert.Equal(t, "http://localhost:8080/c", first.Items[0].ShortURL)
+	assert.NotEmpty(t, first.NextCursor)
+
+	// Execute - second page
+	second, err := service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{
+		Limit:  2,
+		Sort:   model.SortClicks,
+		Domain: "example",
+		Cursor: first.NextCursor,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, second.Items, 1)
+	assert.Equal(t, "a", second.Items[0].ShortCode)
+	assert.Empty(t, second.NextCursor)
+
+	// A cursor is only valid for the sort it was issued for
+	_, err = service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{
+		Sort:   model.SortCreatedAt,
+		Cursor: first.NextCursor,
+	})
+	assert.EqualError(t, err, "invalid cursor")
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListShortURLs_InvalidParameters(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{}, allowAllPolicy{}, noopScanner{}, &config.Config{})
+
+	// Execute & Assert
+	_, err := service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{Sort: "name"})
+	assert.EqualError(t, err, "invalid sort")
+
+	_, err = service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{Order: "up"})
+	assert.EqualError(t, err, "invalid order")
+
+	_, err = service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{Status: "deleted"})
+	assert.EqualError(t, err, "invalid status")
+
+	_, err = service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{Cursor: "%%%"})
+	assert.EqualError(t, err, "invalid cursor")
+
+	mockRepo.AssertNotCalled(t, "List", mock.Anything)
+}
+
+func TestCreateShortURLs_Batch(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+			AliasMinLength:  3,
+			AliasMaxLength:  32,
+			BatchMaxSize:    10,
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Test data
+	items := []model.CreateShortURLRequest{
+		{URL: "https://example.com/1"},
+		{URL: "not a url"},
+		{URL: "https://example.com/2", Alias: "taken"},
+		{URL: "https://example.com/3", Alias: "fresh"},
+		{URL: "https://example.com/4", Alias: "fresh"},
+		{URL: "https://example.com/5", Alias: "api"},
+	}
+
+	// Mock expectations - aliases and generated codes are checked in bulk
+	mockRepo.On("FindExistingCodes", []string{"taken", "fresh"}).Return([]string{"taken"}, nil)
+	mockRepo.On("FindExistingCodes", mock.AnythingOfType("[]string")).Return([]string{}, nil)
+	mockRepo.On("CreateBatch", mock.MatchedBy(func(shortURLs []*model.ShortURL) bool {
+		return len(shortURLs) == 2 && shortURLs[1].ShortCode == "fresh"
+	})).Return(nil)
+	mockCache.On("SetMany", mock.MatchedBy(func(entries []cache.Entry) bool {
+		return len(entries) == 2 && entries[1].Key == "short_url:fresh"
+	})).Return(nil)
+
+	// Execute
+	response, err := service.CreateShortURLs(testAdmin, items)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 4, response.Failed)
+	assert.Len(t, response.Items, len(items))
+
+	assert.Equal(t, model.BatchStatusCreated, response.Items[0].Status)
+	assert.Len(t, response.Items[0].Result.ShortCode, 6)
+	assert.Equal(t, "invalid url", response.Items[1].Error)
+	assert.Equal(t, "alias already in use", response.Items[2].Error)
+	assert.Equal(t, "fresh", response.Items[3].Result.ShortCode)
+	assert.Equal(t, "alias already in use", response.Items[4].Error)
+	assert.Equal(t, "alias is reserved", response.Items[5].Error)
+	for i, item := range response.Items {
+		assert.Equal(t, i, item.Index)
+	}
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestCreateShortURLs_BatchLimits(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BatchMaxSize: 2,
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Execute & Assert
+	_, err := service.CreateShortURLs(testAdmin, nil)
+	assert.EqualError(t, err, "batch is empty")
+
+	_, err = service.CreateShortURLs(testAdmin, make([]model.CreateShortURLRequest, 3))
+	assert.EqualError(t, err, "batch too large")
+
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+}
+
+func TestCreateShortURL_Deduplicate(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+			DeduplicateURLs: true,
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+
+	// Mock expectations - the same destination with another spelling is found
+	existing := &model.ShortURL{ShortCode: "abc123", OriginalURL: "https://example.com/article"}
+	mockRepo.On("FindActiveByHash", "", hashURL("https://example.com/article"), (*time.Time)(nil)).Return(existing, nil)
+
+	// Execute
+	response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "https://EXAMPLE.com:443/article"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, response.Deduplicated)
+	assert.Equal(t, "abc123", response.ShortCode)
+	assert.Equal(t, "http://localhost:8080/abc123", response.ShortURL)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateShortURL_DeduplicateMissAndOptOut(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+	expiresAt := time.Now().Add(time.Hour)
+	urlHash := hashURL("https://example.com/article")
+	enabled, disabled := true, false
+
+	// Mock expectations - nothing with this destination and expiry yet
+	mockRepo.On("FindActiveByHash", "", urlHash, &expiresAt).Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("Create", mock.MatchedBy(func(shortURL *model.ShortURL) bool {
+		return shortURL.URLHash == urlHash
+	})).Return(nil)
+	mockCache.On("Set", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
+
+	// Execute - opted in per request, then opted out
+	response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "https://example.com/article", ExpiresAt: &expiresAt, Deduplicate: &enabled})
+	assert.NoError(t, err)
+	assert.False(t, response.Deduplicated)
+
+	_, err = service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "https://example.com/article", Deduplicate: &disabled})
+	assert.NoError(t, err)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "FindActiveByHash", 1)
+	mockRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestCreateShortURL_NormalizesDestination(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{
+		App: config.AppConfig{
+			BaseURL:         "http://localhost:8080",
+			CacheTTL:        3600,
+			ShortCodeLength: 6,
+		},
+		URLs: config.URLConfig{
+			StripTrackingParams: true,
+			TrackingParams:      []string{"utm_*"},
+		},
+	}
+
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: cfg.App.ShortCodeLength}, allowAllPolicy{}, noopScanner{}, cfg)
+	expected := "https://example.com/article?id=7"
+
+	// Mock expectations - the canonical form is stored, cached and hashed
+	mockRepo.On("Create", mock.MatchedBy(func(shortURL *model.ShortURL) bool {
+		return shortURL.OriginalURL == expected && shortURL.URLHash == hashURL(expected)
+	})).Return(nil)
+	mockCache.On("Set", mock.AnythingOfType("string"), expected, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	// Execute
+	response, err := service.CreateShortURL(testAdmin, &model.CreateShortURLRequest{URL: "HTTPS://Example.com:443/article?id=7&utm_source=newsletter"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, expected, response.OriginalURL)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestShortURLWorkspaceIsolation(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{}, allowAllPolicy{}, noopScanner{}, &config.Config{})
+	member := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, KeyID: 1}
+	outsider := &model.Principal{WorkspaceID: "sales", MemberID: "bob", Role: model.RoleOwner, KeyID: 2}
+	newURL := "https://example.com/fixed"
+
+	// Mock expectations
+	mockRepo.On("FindByCode", "abc123").Return(&model.ShortURL{ShortCode: "abc123", WorkspaceID: "growth"}, nil)
+	mockRepo.On("FindByCode", "legacy").Return(&model.ShortURL{ShortCode: "legacy"}, nil)
+
+	// Execute & Assert - other workspaces can't touch the link, whatever their role
+	_, err := service.GetURLStats(outsider, "abc123")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = service.UpdateShortURL(outsider, "abc123", &model.UpdateShortURLRequest{URL: &newURL})
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, service.DeleteShortURL(outsider, "abc123"), ErrForbidden)
+
+	assert.ErrorIs(t, service.AuthorizeShortURL(outsider, "abc123"), ErrForbidden)
+
+	// Links without a workspace are admin only
+	_, err = service.GetURLStats(member, "legacy")
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, service.AuthorizeShortURL(member, "legacy"), ErrForbidden)
+
+	// Members of the workspace and admins can
+	_, err = service.GetURLStats(member, "abc123")
+	assert.NoError(t, err)
+	_, err = service.GetURLStats(testAdmin, "legacy")
+	assert.NoError(t, err)
+	assert.NoError(t, service.AuthorizeShortURL(member, "abc123"))
+	assert.NoError(t, service.AuthorizeShortURL(testAdmin, "legacy"))
+
+	// Verify mock calls
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestCreateShortURL_DeduplicatedPerWorkspace(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	cfg := &config.Config{App: config.AppConfig{BaseURL: "http://localhost:8080", ShortCodeLength: 6}}
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{length: 6}, allowAllPolicy{}, noopScanner{}, cfg)
+	member := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleEditor, KeyID: 1}
+	urlHash := hashURL("https://example.com/article")
+	enabled := true
+
+	// Mock expectations - another workspace's link with the same destination is not looked at
+	mockRepo.On("FindActiveByHash", "growth", urlHash, (*time.Time)(nil)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("Create", mock.MatchedBy(func(shortURL *model.ShortURL) bool {
+		return shortURL.WorkspaceID == "growth" && shortURL.URLHash == urlHash
+	})).Return(nil)
+	mockCache.On("Set", mock.Anything, "https://example.com/article", mock.Anything).Return(nil)
+
+	// Execute
+	response, err := service.CreateShortURL(member, &model.CreateShortURLRequest{URL: "https://example.com/article", Deduplicate: &enabled})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.ShortCode)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListShortURLs_ScopedToWorkspace(t *testing.T) {
+	// Setup
+	mockRepo := new(MockShortURLRepository)
+	mockCache := new(MockRedisClient)
+	service := NewURLService(mockRepo, mockCache, &randomCodeGenerator{}, allowAllPolicy{}, noopScanner{}, &config.Config{})
+
+	// Mock expectations
+	mockRepo.On("List", mock.MatchedBy(func(f *model.ShortURLFilter) bool {
+		return f.WorkspaceID == "growth"
+	})).Return([]model.ShortURL{}, nil).Once()
+	mockRepo.On("List", mock.MatchedBy(func(f *model.ShortURLFilter) bool {
+		return f.WorkspaceID == ""
+	})).Return([]model.ShortURL{}, nil).Once()
+
+	// Execute
+	_, memberErr := service.ListShortURLs(&model.Principal{WorkspaceID: "growth", Role: model.RoleViewer, KeyID: 1}, &model.ListShortURLsRequest{})
+	_, adminErr := service.ListShortURLs(testAdmin, &model.ListShortURLsRequest{})
+
+	// Assert
+	assert.NoError(t, memberErr)
+	assert.NoError(t, adminErr)
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
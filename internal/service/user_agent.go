@@ -0,0 +1,119 @@
+package service
+
+import "strings"
+
+const (
+	deviceDesktop = "desktop"
+	deviceMobile  = "mobile"
+	deviceTablet  = "tablet"
+	deviceBot     = "bot"
+	unknownValue  = "unknown"
+)
+
+// userAgentInfo is the browser, operating system and device class of a visitor
+type userAgentInfo struct {
+	Browser    string
+	OS         string
+	DeviceType string
+}
+
+// uaToken maps a user agent substring to a display name. Order matters:
+// Chromium based browsers also advertise Chrome and Safari.
+type uaToken struct {
+	token string
+	name  string
+}
+
+var botTokens = []uaToken{
+	{"slackbot", "Slackbot"},
+	{"facebookexternalhit", "Facebook"},
+	{"twitterbot", "Twitterbot"},
+	{"linkedinbot", "LinkedInBot"},
+	{"whatsapp", "WhatsApp"},
+	{"telegrambot", "TelegramBot"},
+	{"discordbot", "Discordbot"},
+	{"googlebot", "Googlebot"},
+	{"bingbot", "Bingbot"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"python-requests", "python-requests"},
+	{"go-http-client", "Go HTTP client"},
+	{"bot", "Other bot"},
+	{"crawler", "Other bot"},
+	{"spider", "Other bot"},
+}
+
+var browserTokens = []uaToken{
+	{"edg/", "Edge"},
+	{"edga/", "Edge"},
+	{"edgios/", "Edge"},
+	{"edge/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"yabrowser/", "Yandex"},
+	{"fban", "Facebook App"},
+	{"instagram", "Instagram App"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"fxios/", "Firefox"},
+	{"firefox/", "Firefox"},
+	{"msie ", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+	{"safari/", "Safari"},
+}
+
+var osTokens = []uaToken{
+	{"windows phone", "Windows Phone"},
+	{"windows nt", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"ipod", "iOS"},
+	{"android", "Android"},
+	{"cros", "Chrome OS"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"linux", "Linux"},
+}
+
+// parseUserAgent classifies a User-Agent header with simple token matching.
+// It does not aim for exact versions, only for the breakdown dimensions.
+func parseUserAgent(userAgent string) userAgentInfo {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return userAgentInfo{Browser: unknownValue, OS: unknownValue, DeviceType: unknownValue}
+	}
+
+	info := userAgentInfo{
+		Browser: matchToken(ua, browserTokens, "Other"),
+		OS:      matchToken(ua, osTokens, "Other"),
+	}
+
+	if bot := matchToken(ua, botTokens, ""); bot != "" {
+		info.Browser = bot
+		info.DeviceType = deviceBot
+		return info
+	}
+
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		info.DeviceType = deviceTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "ipod") ||
+		strings.Contains(ua, "windows phone"):
+		info.DeviceType = deviceMobile
+	default:
+		info.DeviceType = deviceDesktop
+	}
+
+	return info
+}
+
+func matchToken(ua string, tokens []uaToken, fallback string) string {
+	for _, t := range tokens {
+		if strings.Contains(ua, t.token) {
+			return t.name
+		}
+	}
+	return fallback
+}
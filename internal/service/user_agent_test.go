@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		expected  userAgentInfo
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			userAgentInfo{Browser: "Chrome", OS: "Windows", DeviceType: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+			userAgentInfo{Browser: "Edge", OS: "Windows", DeviceType: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
+			userAgentInfo{Browser: "Safari", OS: "macOS", DeviceType: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			userAgentInfo{Browser: "Firefox", OS: "Linux", DeviceType: "desktop"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			userAgentInfo{Browser: "Chrome", OS: "Android", DeviceType: "mobile"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			userAgentInfo{Browser: "Chrome", OS: "Android", DeviceType: "tablet"},
+		},
+		{
+			"Mozilla/5.0 (iPad; CPU OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			userAgentInfo{Browser: "Chrome", OS: "iOS", DeviceType: "tablet"},
+		},
+		{
+			"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			userAgentInfo{Browser: "Slackbot", OS: "Other", DeviceType: "bot"},
+		},
+		{
+			"curl/8.4.0",
+			userAgentInfo{Browser: "curl", OS: "Other", DeviceType: "bot"},
+		},
+		{
+			"",
+			userAgentInfo{Browser: "unknown", OS: "unknown", DeviceType: "unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, parseUserAgent(tt.userAgent), tt.userAgent)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shortener/internal/model"
+	"github.com/shortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// roleRanks orders workspace roles, a role has the permissions of every lower rank
+var roleRanks = map[string]int{
+	model.RoleViewer: 1,
+	model.RoleEditor: 2,
+	model.RoleAdmin:  3,
+	model.RoleOwner:  4,
+}
+
+// HasRole reports whether the principal has at least the given role in its
+// workspace. Instance admins have every role.
+func HasRole(principal *model.Principal, role string) bool {
+	if principal == nil {
+		return false
+	}
+	if principal.Admin {
+		return true
+	}
+	rank, ok := roleRanks[principal.Role]
+	return ok && rank >= roleRanks[role]
+}
+
+// WorkspaceService manages workspaces and their members. Workspaces of other
+// teams look missing to non-admins.
+type WorkspaceService interface {
+	CreateWorkspace(req *model.CreateWorkspaceRequest) (*model.Workspace, error)
+	ListWorkspaces(principal *model.Principal) (*model.ListWorkspacesResponse, error)
+	ListMembers(principal *model.Principal, workspaceID string) (*model.ListWorkspaceMembersResponse, error)
+	AddMember(principal *model.Principal, workspaceID string, req *model.AddWorkspaceMemberRequest) (*model.WorkspaceMember, error)
+	UpdateMember(principal *model.Principal, workspaceID, memberID string, req *model.UpdateWorkspaceMemberRequest) (*model.WorkspaceMember, error)
+	RemoveMember(principal *model.Principal, workspaceID, memberID string) error
+}
+
+type workspaceService struct {
+	repo repository.WorkspaceRepository
+}
+
+func NewWorkspaceService(repo repository.WorkspaceRepository) WorkspaceService {
+	return &workspaceService{repo: repo}
+}
+
+// CreateWorkspace creates a workspace with its first owner
+func (s *workspaceService) CreateWorkspace(req *model.CreateWorkspaceRequest) (*model.Workspace, error) {
+	id := strings.TrimSpace(req.ID)
+	if !aliasPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid workspace id")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	owner := strings.TrimSpace(req.Owner)
+	if owner == "" {
+		return nil, fmt.Errorf("member_id is required")
+	}
+
+	workspace := &model.Workspace{ID: id, Name: name}
+	if err := s.repo.CreateWithOwner(workspace, &model.WorkspaceMember{MemberID: owner, Role: model.RoleOwner}); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fmt.Errorf("workspace already exists")
+		}
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// ListWorkspaces returns every workspace for admins and the caller's own otherwise
+func (s *workspaceService) ListWorkspaces(principal *model.Principal) (*model.ListWorkspacesResponse, error) {
+	if !principal.Admin {
+		workspace, err := s.repo.FindByID(principal.WorkspaceID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return &model.ListWorkspacesResponse{Items: []model.Workspace{}}, nil
+			}
+			return nil, fmt.Errorf("failed to find workspace: %w", err)
+		}
+		return &model.ListWorkspacesResponse{Items: []model.Workspace{*workspace}}, nil
+	}
+
+	workspaces, err := s.repo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	if workspaces == nil {
+		workspaces = []model.Workspace{}
+	}
+	return &model.ListWorkspacesResponse{Items: workspaces}, nil
+}
+
+func (s *workspaceService) ListMembers(principal *model.Principal, workspaceID string) (*model.ListWorkspaceMembersResponse, error) {
+	if err := s.checkWorkspace(principal, workspaceID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	if members == nil {
+		members = []model.WorkspaceMember{}
+	}
+	return &model.ListWorkspaceMembersResponse{Items: members}, nil
+}
+
+// AddMember adds a member. Admins add editors and viewers, only owners add
+// admins and owners.
+func (s *workspaceService) AddMember(principal *model.Principal, workspaceID string, req *model.AddWorkspaceMemberRequest) (*model.WorkspaceMember, error) {
+	memberID := strings.TrimSpace(req.MemberID)
+	if memberID == "" {
+		return nil, fmt.Errorf("member_id is required")
+	}
+	if _, ok := roleRanks[req.Role]; !ok {
+		return nil, fmt.Errorf("invalid role")
+	}
+	if err := s.checkWorkspace(principal, workspaceID); err != nil {
+		return nil, err
+	}
+	if !canManageRole(principal, req.Role) {
+		return nil, ErrForbidden
+	}
+
+	member := &model.WorkspaceMember{WorkspaceID: workspaceID, MemberID: memberID, Role: req.Role}
+	if err := s.repo.AddMember(member); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fmt.Errorf("member already exists")
+		}
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	return member, nil
+}
+
+// UpdateMember changes a member's role. Changing an owner or admin, or making
+// someone one, requires the owner role.
+func (s *workspaceService) UpdateMember(principal *model.Principal, workspaceID, memberID string, req *model.UpdateWorkspaceMemberRequest) (*model.WorkspaceMember, error) {
+	if _, ok := roleRanks[req.Role]; !ok {
+		return nil, fmt.Errorf("invalid role")
+	}
+	member, err := s.findManagedMember(principal, workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageRole(principal, req.Role) {
+		return nil, ErrForbidden
+	}
+	if member.Role == model.RoleOwner && req.Role != model.RoleOwner {
+		if err := s.checkNotLastOwner(workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateMemberRole(workspaceID, member.MemberID, req.Role); err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	member.Role = req.Role
+	return member, nil
+}
+
+// RemoveMember removes a member, their API keys stop working right away
+func (s *workspaceService) RemoveMember(principal *model.Principal, workspaceID, memberID string) error {
+	member, err := s.findManagedMember(principal, workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == model.RoleOwner {
+		if err := s.checkNotLastOwner(workspaceID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.RemoveMember(workspaceID, member.MemberID); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// checkWorkspace hides other workspaces from non-admins and makes sure the
+// workspace exists
+func (s *workspaceService) checkWorkspace(principal *model.Principal, workspaceID string) error {
+	if !principal.Admin && principal.WorkspaceID != workspaceID {
+		return fmt.Errorf("workspace not found")
+	}
+	if _, err := s.repo.FindByID(workspaceID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("workspace not found")
+		}
+		return fmt.Errorf("failed to find workspace: %w", err)
+	}
+	return nil
+}
+
+// findManagedMember loads a member the principal may change or remove
+func (s *workspaceService) findManagedMember(principal *model.Principal, workspaceID, memberID string) (*model.WorkspaceMember, error) {
+	if err := s.checkWorkspace(principal, workspaceID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.FindMember(workspaceID, memberID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("member not found")
+		}
+		return nil, fmt.Errorf("failed to find member: %w", err)
+	}
+	if !canManageRole(principal, member.Role) {
+		return nil, ErrForbidden
+	}
+	return member, nil
+}
+
+// checkNotLastOwner keeps at least one owner in every workspace
+func (s *workspaceService) checkNotLastOwner(workspaceID string) error {
+	owners, err := s.repo.CountMembersWithRole(workspaceID, model.RoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners <= 1 {
+		return fmt.Errorf("last owner")
+	}
+	return nil
+}
+
+// canManageRole reports whether the principal may grant, change or revoke the
+// role. Managing members needs the admin role, managing admins and owners the owner role.
+func canManageRole(principal *model.Principal, role string) bool {
+	if role == model.RoleOwner || role == model.RoleAdmin {
+		return HasRole(principal, model.RoleOwner)
+	}
+	return HasRole(principal, model.RoleAdmin)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shortener/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockWorkspaceRepository is a mock implementation of WorkspaceRepository
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) CreateWithOwner(workspace *model.Workspace, owner *model.WorkspaceMember) error {
+	args := m.Called(workspace, owner)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) FindByID(id string) (*model.Workspace, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) List() ([]model.Workspace, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) AddMember(member *model.WorkspaceMember) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) FindMember(workspaceID, memberID string) (*model.WorkspaceMember, error) {
+	args := m.Called(workspaceID, memberID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) ListMembers(workspaceID string) ([]model.WorkspaceMember, error) {
+	args := m.Called(workspaceID)
+	return args.Get(0).([]model.WorkspaceMember), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) CountMembersWithRole(workspaceID, role string) (int64, error) {
+	args := m.Called(workspaceID, role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) UpdateMemberRole(workspaceID, memberID, role string) error {
+	args := m.Called(workspaceID, memberID, role)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) RemoveMember(workspaceID, memberID string) error {
+	args := m.Called(workspaceID, memberID)
+	return args.Error(0)
+}
+
+func TestHasRole(t *testing.T) {
+	editor := &model.Principal{WorkspaceID: "growth", Role: model.RoleEditor}
+
+	assert.True(t, HasRole(editor, model.RoleViewer))
+	assert.True(t, HasRole(editor, model.RoleEditor))
+	assert.False(t, HasRole(editor, model.RoleAdmin))
+	assert.False(t, HasRole(&model.Principal{}, model.RoleViewer))
+	assert.False(t, HasRole(nil, model.RoleViewer))
+	assert.True(t, HasRole(&model.Principal{Admin: true}, model.RoleOwner))
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	// Setup
+	mockRepo := new(MockWorkspaceRepository)
+	service := NewWorkspaceService(mockRepo)
+
+	// Mock expectations
+	mockRepo.On("CreateWithOwner", &model.Workspace{ID: "growth", Name: "Growth"},
+		&model.WorkspaceMember{MemberID: "alice", Role: model.RoleOwner}).Return(nil).Once()
+	mockRepo.On("CreateWithOwner", mock.Anything, mock.Anything).Return(gorm.ErrDuplicatedKey).Once()
+
+	// Execute
+	workspace, err := service.CreateWorkspace(&model.CreateWorkspaceRequest{ID: "growth", Name: " Growth ", Owner: "alice"})
+	_, duplicateErr := service.CreateWorkspace(&model.CreateWorkspaceRequest{ID: "growth", Name: "Growth", Owner: "alice"})
+	_, invalidErr := service.CreateWorkspace(&model.CreateWorkspaceRequest{ID: "growth team", Name: "Growth", Owner: "alice"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "growth", workspace.ID)
+	assert.EqualError(t, duplicateErr, "workspace already exists")
+	assert.EqualError(t, invalidErr, "invalid workspace id")
+
+	// Verify mock calls
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddMember_RoleRules(t *testing.T) {
+	// Setup
+	mockRepo := new(MockWorkspaceRepository)
+	service := NewWorkspaceService(mockRepo)
+	admin := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleAdmin}
+	owner := &model.Principal{WorkspaceID: "growth", MemberID: "olivia", Role: model.RoleOwner}
+
+	// Mock expectations
+	mockRepo.On("FindByID", "growth").Return(&model.Workspace{ID: "growth"}, nil)
+	mockRepo.On("AddMember", mock.Anything).Return(nil)
+
+	// Execute
+	editor, editorErr := service.AddMember(admin, "growth", &model.AddWorkspaceMemberRequest{MemberID: "bob", Role: model.RoleEditor})
+	_, escalateErr := service.AddMember(admin, "growth", &model.AddWorkspaceMemberRequest{MemberID: "carol", Role: model.RoleAdmin})
+	_, ownerErr := service.AddMember(owner, "growth", &model.AddWorkspaceMemberRequest{MemberID: "carol", Role: model.RoleAdmin})
+	_, otherWorkspaceErr := service.AddMember(owner, "sales", &model.AddWorkspaceMemberRequest{MemberID: "dave", Role: model.RoleViewer})
+	_, roleErr := service.AddMember(owner, "growth", &model.AddWorkspaceMemberRequest{MemberID: "dave", Role: "superuser"})
+
+	// Assert
+	assert.NoError(t, editorErr)
+	assert.Equal(t, "growth", editor.WorkspaceID)
+	assert.ErrorIs(t, escalateErr, ErrForbidden)
+	assert.NoError(t, ownerErr)
+	assert.EqualError(t, otherWorkspaceErr, "workspace not found")
+	assert.EqualError(t, roleErr, "invalid role")
+
+	// Verify mock calls
+	mockRepo.AssertNumberOfCalls(t, "AddMember", 2)
+}
+
+func TestUpdateAndRemoveMember_LastOwner(t *testing.T) {
+	// Setup
+	mockRepo := new(MockWorkspaceRepository)
+	service := NewWorkspaceService(mockRepo)
+	owner := &model.Principal{WorkspaceID: "growth", MemberID: "olivia", Role: model.RoleOwner}
+
+	// Mock expectations
+	mockRepo.On("FindByID", "growth").Return(&model.Workspace{ID: "growth"}, nil)
+	mockRepo.On("FindMember", "growth", "olivia").Return(&model.WorkspaceMember{WorkspaceID: "growth", MemberID: "olivia", Role: model.RoleOwner}, nil)
+	mockRepo.On("CountMembersWithRole", "growth", model.RoleOwner).Return(int64(1), nil)
+
+	// Execute
+	_, demoteErr := service.UpdateMember(owner, "growth", "olivia", &model.UpdateWorkspaceMemberRequest{Role: model.RoleAdmin})
+	removeErr := service.RemoveMember(owner, "growth", "olivia")
+
+	// Assert
+	assert.EqualError(t, demoteErr, "last owner")
+	assert.EqualError(t, removeErr, "last owner")
+
+	// Verify mock calls
+	mockRepo.AssertNotCalled(t, "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+}
+
+func TestRemoveMember_AdminCannotRemoveAdmins(t *testing.T) {
+	// Setup
+	mockRepo := new(MockWorkspaceRepository)
+	service := NewWorkspaceService(mockRepo)
+	admin := &model.Principal{WorkspaceID: "growth", MemberID: "alice", Role: model.RoleAdmin}
+
+	// Mock expectations
+	mockRepo.On("FindByID", "growth").Return(&model.Workspace{ID: "growth"}, nil)
+	mockRepo.On("FindMember", "growth", "carol").Return(&model.WorkspaceMember{MemberID: "carol", Role: model.RoleAdmin}, nil)
+	mockRepo.On("FindMember", "growth", "bob").Return(&model.WorkspaceMember{MemberID: "bob", Role: model.RoleViewer}, nil)
+	mockRepo.On("RemoveMember", "growth", "bob").Return(nil)
+
+	// Execute
+	adminErr := service.RemoveMember(admin, "growth", "carol")
+	viewerErr := service.RemoveMember(admin, "growth", "bob")
+
+	// Assert
+	assert.ErrorIs(t, adminErr, ErrForbidden)
+	assert.NoError(t, viewerErr)
+
+	// Verify mock calls
+	mockRepo.AssertNumberOfCalls(t, "RemoveMember", 1)
+}